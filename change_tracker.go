@@ -0,0 +1,103 @@
+package spiffy
+
+import (
+	"database/sql/driver"
+	"reflect"
+)
+
+// ChangeTracker can be embedded in a database mapped type to track which of its columns have changed since it was read.
+// Objects read with `Get` or `GetAll` (and written with `Create` or `Update`) take a snapshot of their column values,
+// and `UpdateChanged` then only writes the columns that differ from it.
+//
+//	type User struct {
+//		spiffy.ChangeTracker
+//		ID     int    `db:"id,pk,serial"`
+//		Status string `db:"status"`
+//	}
+type ChangeTracker struct {
+	snapshot map[string]interface{}
+}
+
+// IsTracking returns if the tracker has a snapshot to compare against.
+func (ct *ChangeTracker) IsTracking() bool {
+	return ct.snapshot != nil
+}
+
+// ResetChanges discards the snapshot, so the next `UpdateChanged` writes every column.
+func (ct *ChangeTracker) ResetChanges() {
+	ct.snapshot = nil
+}
+
+// changeTracker returns the tracker; it is promoted to the types that embed it.
+func (ct *ChangeTracker) changeTracker() *ChangeTracker {
+	return ct
+}
+
+// changeTracked is implemented by pointers to types that embed a `ChangeTracker`.
+type changeTracked interface {
+	changeTracker() *ChangeTracker
+}
+
+// takeSnapshot records the current values of a set of columns of an object if it tracks changes.
+// Columns that are not in the set keep their previous snapshot values, if any.
+func takeSnapshot(object DatabaseMapped, cols *ColumnCollection) {
+	tracked, isTracked := object.(changeTracked)
+	if !isTracked {
+		return
+	}
+
+	tracker := tracked.changeTracker()
+	if tracker.snapshot == nil {
+		tracker.snapshot = make(map[string]interface{}, cols.Len())
+	}
+	values := snapshotValues(object, cols)
+	for x, col := range cols.Columns() {
+		tracker.snapshot[col.ColumnName] = values[x]
+	}
+}
+
+// resetSnapshot replaces the snapshot of an object that tracks changes with the current values of a set of columns.
+func resetSnapshot(object DatabaseMapped, cols *ColumnCollection) {
+	if tracked, isTracked := object.(changeTracked); isTracked {
+		tracked.changeTracker().ResetChanges()
+		takeSnapshot(object, cols)
+	}
+}
+
+// changedColumns returns the columns of a collection whose values differ from the object's snapshot.
+// If the object does not track changes or has no snapshot, it returns false.
+func changedColumns(object DatabaseMapped, cols *ColumnCollection) (*ColumnCollection, bool) {
+	tracked, isTracked := object.(changeTracked)
+	if !isTracked || !tracked.changeTracker().IsTracking() {
+		return nil, false
+	}
+
+	snapshot := tracked.changeTracker().snapshot
+	values := snapshotValues(object, cols)
+	var changed []Column
+	for x, col := range cols.Columns() {
+		previous, hasPrevious := snapshot[col.ColumnName]
+		if !hasPrevious || !reflect.DeepEqual(previous, values[x]) {
+			changed = append(changed, col)
+		}
+	}
+	return newColumnCollectionFromColumns(changed), true
+}
+
+// snapshotValues returns the values of a set of columns of an object as the driver would write them.
+// Encoding the values copies what the object holds by reference (slices, maps and pointers),
+// so changes made to them in place still differ from the snapshot.
+func snapshotValues(object DatabaseMapped, cols *ColumnCollection) []interface{} {
+	values, _ := cols.columnValues(object)
+	for x, value := range values {
+		encoded, err := driver.DefaultParameterConverter.ConvertValue(value)
+		if err != nil {
+			continue
+		}
+		if contents, isBytes := encoded.([]byte); isBytes {
+			encoded = append([]byte(nil), contents...)
+		}
+		values[x] = encoded
+	}
+	return values
+}
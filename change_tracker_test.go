@@ -0,0 +1,86 @@
+package spiffy
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+type trackedObj struct {
+	ChangeTracker
+	ID        int       `db:"id,pk,serial"`
+	Name      string    `db:"name"`
+	Timestamp time.Time `db:"timestamp_utc"`
+	Amount    float32   `db:"amount"`
+	Pending   bool      `db:"pending"`
+	Category  string    `db:"category"`
+}
+
+func (to trackedObj) TableName() string {
+	return "bench_object"
+}
+
+func TestChangeTrackerChangedColumns(t *testing.T) {
+	a := assert.New(t)
+
+	obj := &trackedObj{ID: 1, Name: "foo", Category: "bar"}
+	cols := Columns(obj).WriteColumns()
+
+	_, isTracked := changedColumns(obj, cols)
+	a.False(isTracked)
+
+	_, isTracked = changedColumns(&benchObj{}, Columns(benchObj{}).WriteColumns())
+	a.False(isTracked)
+
+	resetSnapshot(obj, Columns(obj))
+	a.True(obj.IsTracking())
+
+	changed, isTracked := changedColumns(obj, cols)
+	a.True(isTracked)
+	a.Zero(changed.Len())
+
+	obj.Name = "not foo"
+	obj.Pending = true
+	changed, _ = changedColumns(obj, cols)
+	a.Equal([]string{"name", "pending"}, changed.ColumnNames())
+
+	takeSnapshot(obj, newColumnCollectionFromColumns([]Column{*cols.Lookup()["name"]}))
+	changed, _ = changedColumns(obj, cols)
+	a.Equal([]string{"pending"}, changed.ColumnNames())
+
+	obj.ResetChanges()
+	a.False(obj.IsTracking())
+}
+
+type trackedRefObj struct {
+	ChangeTracker
+	ID     int               `db:"id,pk,serial"`
+	Name   *string           `db:"name"`
+	Raw    []byte            `db:"raw"`
+	Tags   []string          `db:"tags,array"`
+	Labels map[string]string `db:"labels,json"`
+}
+
+func (to trackedRefObj) TableName() string {
+	return "tracked_ref_object"
+}
+
+func TestChangeTrackerInPlaceChanges(t *testing.T) {
+	a := assert.New(t)
+
+	name := "foo"
+	obj := &trackedRefObj{ID: 1, Name: &name, Raw: []byte("raw"), Tags: []string{"a"}, Labels: map[string]string{"a": "b"}}
+	cols := Columns(obj).WriteColumns()
+	resetSnapshot(obj, Columns(obj))
+
+	changed, _ := changedColumns(obj, cols)
+	a.Zero(changed.Len())
+
+	*obj.Name = "not foo"
+	obj.Raw[0] = 'w'
+	obj.Tags[0] = "b"
+	obj.Labels["a"] = "c"
+	changed, _ = changedColumns(obj, cols)
+	a.Equal([]string{"name", "raw", "tags", "labels"}, changed.ColumnNames())
+}
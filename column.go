@@ -3,9 +3,11 @@ package spiffy
 import (
 	"encoding/json"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/blendlabs/go-exception"
+	"github.com/lib/pq"
 )
 
 // --------------------------------------------------------------------------------
@@ -28,12 +30,28 @@ func NewColumnFromFieldTag(field reflect.StructField) *Column {
 			}
 
 			if len(pieces) >= 1 {
-				args := strings.Join(pieces[1:], ",")
+				// options with values (e.g. `maxlen=255`) are read separately so their values can't match the flags below.
+				var flags []string
+				for _, piece := range pieces[1:] {
+					if separator := strings.Index(piece, "="); separator >= 0 {
+						col.setTagOption(strings.ToLower(strings.TrimSpace(piece[:separator])), strings.TrimSpace(piece[separator+1:]))
+						continue
+					}
+					flags = append(flags, piece)
+				}
+
+				args := strings.Join(flags, ",")
 				col.IsPrimaryKey = strings.Contains(strings.ToLower(args), "pk")
 				col.IsSerial = strings.Contains(strings.ToLower(args), "serial")
 				col.IsNullable = strings.Contains(strings.ToLower(args), "nullable")
 				col.IsReadOnly = strings.Contains(strings.ToLower(args), "readonly")
 				col.IsJSON = strings.Contains(strings.ToLower(args), "json")
+				col.IsArray = strings.Contains(strings.ToLower(args), "array")
+				col.IsVersion = strings.Contains(strings.ToLower(args), "version")
+				col.IsSoftDelete = strings.Contains(strings.ToLower(args), "softdelete")
+				col.IsAutoCreate = strings.Contains(strings.ToLower(args), "autocreate")
+				col.IsAutoUpdate = strings.Contains(strings.ToLower(args), "autoupdate")
+				col.IsRequired = strings.Contains(strings.ToLower(args), "required")
 			}
 		}
 		return &col
@@ -54,12 +72,38 @@ type Column struct {
 	IsNullable   bool
 	IsReadOnly   bool
 	IsJSON       bool
+	IsArray      bool
+	IsVersion    bool
+	IsSoftDelete bool
+	IsAutoCreate bool
+	IsAutoUpdate bool
+	IsRequired   bool
+	MaxLength    int
+	Enum         []string
+
+	// indexPath is the path of field indexes to the field from the mapped type, through any embedded structs.
+	indexPath []int
+}
+
+// setTagOption sets a column option that has a value, e.g. `maxlen=255` or `enum=active|inactive`.
+func (c *Column) setTagOption(key, value string) {
+	switch key {
+	case "maxlen":
+		if maxLength, err := strconv.Atoi(value); err == nil {
+			c.MaxLength = maxLength
+		}
+	case "enum":
+		c.Enum = strings.Split(value, "|")
+	}
 }
 
 // SetValue sets the field on a database mapped object to the instance of `value`.
 func (c Column) SetValue(object interface{}, value interface{}) error {
 	objValue := reflectValue(object)
-	field := objValue.FieldByName(c.FieldName)
+	field := c.fieldValue(objValue, true)
+	if !field.IsValid() {
+		return exception.New("hit a field we can't set: '" + c.FieldName + "', did you forget to pass the object as a reference?")
+	}
 	fieldType := field.Type()
 	if !field.CanSet() {
 		return exception.New("hit a field we can't set: '" + c.FieldName + "', did you forget to pass the object as a reference?")
@@ -70,17 +114,16 @@ func (c Column) SetValue(object interface{}, value interface{}) error {
 		return nil
 	}
 
+	if c.IsArray {
+		return setArrayValue(field, value)
+	}
+
 	if c.IsJSON {
-		valueAsString, ok := valueReflected.Interface().(string)
-		if ok && len(valueAsString) != 0 {
-			fieldAddr := field.Addr().Interface()
-			jsonErr := json.Unmarshal([]byte(valueAsString), fieldAddr)
-			if jsonErr != nil {
-				return exception.Wrap(jsonErr)
-			}
-			field.Set(reflect.ValueOf(fieldAddr).Elem())
-		}
-		return nil
+		return c.setJSONValue(field, valueReflected)
+	}
+
+	if converter, hasConverter := typeConverterFor(fieldType); hasConverter {
+		return setConvertedValue(field, converter, valueReflected)
 	}
 
 	if valueReflected.Type().AssignableTo(fieldType) {
@@ -115,8 +158,163 @@ func (c Column) SetValue(object interface{}, value interface{}) error {
 }
 
 // GetValue returns the value for a column on a given database mapped object.
+// The field is returned as it is, even for `array` columns and columns with a type converter;
+// they are only encoded for the driver in the values written by `ColumnValues`.
 func (c Column) GetValue(object DatabaseMapped) interface{} {
 	value := reflectValue(object)
-	valueField := value.Field(c.Index)
+	valueField := c.fieldValue(value, false)
+	if !valueField.IsValid() {
+		return nil
+	}
 	return valueField.Interface()
 }
+
+// scanTarget returns a new destination to scan a value of the column into from a row.
+// Columns with a type converter are scanned as the driver's value and decoded when they're set.
+func (c Column) scanTarget() interface{} {
+	if c.IsJSON {
+		return new([]byte)
+	}
+	if c.IsArray {
+		return arrayScanTarget(c.FieldType)
+	}
+	if _, hasConverter := typeConverterFor(c.FieldType); hasConverter {
+		var value interface{}
+		return &value
+	}
+	return reflect.New(reflect.PtrTo(c.FieldType)).Interface()
+}
+
+// jsonValue marshals the value of a `json` column for a write; nil references, maps, slices and interfaces are written as null.
+func (c Column) jsonValue(field reflect.Value) (interface{}, error) {
+	switch field.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if field.IsNil() {
+			return nil, nil
+		}
+	}
+
+	contents, err := json.Marshal(field.Interface())
+	if err != nil {
+		return nil, newJSONError(c, err)
+	}
+	return string(contents), nil
+}
+
+// setJSONValue unmarshals a `json` column read as a string or bytes (i.e. for `jsonb`) into a field.
+// Null or empty values set the field to its zero value.
+func (c Column) setJSONValue(field reflect.Value, value reflect.Value) error {
+	var contents []byte
+	switch value.Kind() {
+	case reflect.String:
+		contents = []byte(value.String())
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.Uint8 {
+			return newJSONError(c, exception.Newf("cannot unmarshal a `%s`", value.Type().String()))
+		}
+		contents = value.Bytes()
+	default:
+		return newJSONError(c, exception.Newf("cannot unmarshal a `%s`", value.Type().String()))
+	}
+
+	field.Set(reflect.Zero(field.Type()))
+	if len(contents) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(contents, field.Addr().Interface()); err != nil {
+		return newJSONError(c, err)
+	}
+	return nil
+}
+
+// arrayValue wraps the value of an `array` column so the driver writes it as a postgres array.
+// Nil slices (and nil references to slices) are written as null.
+func arrayValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		value = v.Elem().Interface()
+	}
+	return pq.Array(value)
+}
+
+// arrayScanTarget returns a new destination to scan a postgres array into for a slice (or reference to a slice) type.
+// Slices of bool, float64, int64, string and []byte are scanned natively, other element types must implement `sql.Scanner`.
+func arrayScanTarget(fieldType reflect.Type) interface{} {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	target := pq.Array(reflect.New(fieldType).Interface())
+	if generic, isGeneric := target.(pq.GenericArray); isGeneric {
+		return &generic
+	}
+	return target
+}
+
+// setArrayValue sets a slice (or reference to a slice) field from a scanned postgres array; null arrays set it to nil.
+func setArrayValue(field reflect.Value, value interface{}) error {
+	switch typed := value.(type) {
+	case pq.GenericArray:
+		value = typed.A
+	case *pq.GenericArray:
+		value = typed.A
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	sliceType := field.Type()
+	if sliceType.Kind() == reflect.Ptr {
+		sliceType = sliceType.Elem()
+	}
+	if v.Kind() != reflect.Slice || !v.Type().ConvertibleTo(sliceType) {
+		return exception.Newf("cannot set an array column of type `%s` from a `%s`.", field.Type().String(), v.Type().String())
+	}
+	if v.IsNil() {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	converted := v.Convert(sliceType)
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(sliceType)
+		ptr.Elem().Set(converted)
+		field.Set(ptr)
+		return nil
+	}
+	field.Set(converted)
+	return nil
+}
+
+// fieldValue returns the field for the column on a struct value, following the path through any embedded structs.
+// Nil embedded struct pointers along the path are allocated if `alloc` is set (and they can be),
+// otherwise an invalid value is returned for them.
+func (c Column) fieldValue(value reflect.Value, alloc bool) reflect.Value {
+	if len(c.indexPath) == 0 {
+		return value.FieldByName(c.FieldName)
+	}
+
+	for x, index := range c.indexPath {
+		if x > 0 {
+			for value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					if !alloc || !value.CanSet() {
+						return reflect.Value{}
+					}
+					value.Set(reflect.New(value.Type().Elem()))
+				}
+				value = value.Elem()
+			}
+		}
+		value = value.Field(index)
+	}
+	return value
+}
@@ -1,11 +1,12 @@
 package spiffy
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
+
+	exception "github.com/blendlabs/go-exception"
 )
 
 var (
@@ -95,28 +96,64 @@ func getCachedColumnCollectionFromType(identifier string, t reflect.Type) *Colum
 }
 
 // GenerateColumnCollectionForType reflects a new column collection from a reflect.Type.
+// If two fields map to the same column the collection's `Err()` is set.
 func generateColumnCollectionForType(t reflect.Type) *ColumnCollection {
 	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
 	tableName, _ := TableName(t)
-	numFields := t.NumField()
+	cols := generateColumnsForType(t, tableName, "", nil)
+
+	fieldNames := map[string]string{}
+	for _, col := range cols {
+		if existing, hasExisting := fieldNames[col.ColumnName]; hasExisting {
+			cc := newColumnCollectionFromColumns(cols)
+			cc.err = exception.Newf("column `%s` of `%s` is mapped by both `%s` and `%s`; use a prefix on the embedded struct.", col.ColumnName, tableName, existing, col.FieldName)
+			return cc
+		}
+		fieldNames[col.ColumnName] = col.FieldName
+	}
+
+	return newColumnCollectionFromColumns(cols)
+}
 
+// generateColumnsForType reads the columns of the fields of a struct type, flattening embedded structs into them.
+// The db tag of an embedded struct is a prefix for its column names, e.g. `db:"audit_"`, and `db:"-"` skips it.
+// Unexported fields of embedded structs are skipped.
+func generateColumnsForType(t reflect.Type, tableName, prefix string, path []int) []Column {
 	var cols []Column
-	for index := 0; index < numFields; index++ {
+	for index := 0; index < t.NumField(); index++ {
 		field := t.Field(index)
-		if !field.Anonymous {
-			col := NewColumnFromFieldTag(field)
-			if col != nil {
-				col.Index = index
-				col.TableName = tableName
-				cols = append(cols, *col)
+		fieldPath := append(append([]int{}, path...), index)
+
+		if field.Anonymous {
+			embeddedType := field.Type
+			for embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			embeddedPrefix := field.Tag.Get("db")
+			if embeddedPrefix == "-" || embeddedType.Kind() != reflect.Struct || len(field.PkgPath) > 0 {
+				continue
 			}
+			cols = append(cols, generateColumnsForType(embeddedType, tableName, prefix+embeddedPrefix, fieldPath)...)
+			continue
 		}
-	}
 
-	return newColumnCollectionFromColumns(cols)
+		if len(path) > 0 && len(field.PkgPath) > 0 {
+			continue
+		}
+
+		col := NewColumnFromFieldTag(field)
+		if col != nil {
+			col.Index = index
+			col.indexPath = fieldPath
+			col.ColumnName = prefix + col.ColumnName
+			col.TableName = tableName
+			cols = append(cols, *col)
+		}
+	}
+	return cols
 }
 
 // ColumnCollection represents the column metadata for a given struct.
@@ -124,6 +161,7 @@ type ColumnCollection struct {
 	columns      []Column
 	lookup       map[string]*Column
 	columnPrefix string
+	err          error
 
 	serials        *ColumnCollection
 	notSerials     *ColumnCollection
@@ -131,10 +169,21 @@ type ColumnCollection struct {
 	notReadOnly    *ColumnCollection
 	primaryKeys    *ColumnCollection
 	notPrimaryKeys *ColumnCollection
+	versions       *ColumnCollection
+	notVersions    *ColumnCollection
+	softDeletes    *ColumnCollection
+	notSoftDeletes *ColumnCollection
+	autoUpdates    *ColumnCollection
+	notAutoCreates *ColumnCollection
 	writeColumns   *ColumnCollection
 	updateColumns  *ColumnCollection
 }
 
+// Err returns the error reflecting the collection's type, i.e. two fields that map to the same column.
+func (cc *ColumnCollection) Err() error {
+	return cc.err
+}
+
 // Len returns the number of columns.
 func (cc *ColumnCollection) Len() int {
 	return len(cc.columns)
@@ -264,6 +313,114 @@ func (cc *ColumnCollection) NotSerials() *ColumnCollection {
 	return cc.notSerials
 }
 
+// Versions are optimistic locking columns that are checked and incremented on Update() and Upsert().
+func (cc *ColumnCollection) Versions() *ColumnCollection {
+	if cc.versions != nil {
+		return cc.versions
+	}
+
+	newCC := newColumnCollectionWithPrefix(cc.columnPrefix)
+
+	for _, c := range cc.columns {
+		if c.IsVersion {
+			newCC.Add(c)
+		}
+	}
+
+	cc.versions = newCC
+	return cc.versions
+}
+
+// SoftDeletes are columns that mark a row as deleted when set, instead of the row being removed on Delete().
+func (cc *ColumnCollection) SoftDeletes() *ColumnCollection {
+	if cc.softDeletes != nil {
+		return cc.softDeletes
+	}
+
+	newCC := newColumnCollectionWithPrefix(cc.columnPrefix)
+
+	for _, c := range cc.columns {
+		if c.IsSoftDelete {
+			newCC.Add(c)
+		}
+	}
+
+	cc.softDeletes = newCC
+	return cc.softDeletes
+}
+
+// NotSoftDeletes are columns that do not mark a row as deleted.
+func (cc *ColumnCollection) NotSoftDeletes() *ColumnCollection {
+	if cc.notSoftDeletes != nil {
+		return cc.notSoftDeletes
+	}
+
+	newCC := newColumnCollectionWithPrefix(cc.columnPrefix)
+
+	for _, c := range cc.columns {
+		if !c.IsSoftDelete {
+			newCC.Add(c)
+		}
+	}
+
+	cc.notSoftDeletes = newCC
+	return cc.notSoftDeletes
+}
+
+// AutoUpdates are timestamp columns that are set to the connection's clock on every write.
+func (cc *ColumnCollection) AutoUpdates() *ColumnCollection {
+	if cc.autoUpdates != nil {
+		return cc.autoUpdates
+	}
+
+	newCC := newColumnCollectionWithPrefix(cc.columnPrefix)
+
+	for _, c := range cc.columns {
+		if c.IsAutoUpdate {
+			newCC.Add(c)
+		}
+	}
+
+	cc.autoUpdates = newCC
+	return cc.autoUpdates
+}
+
+// NotAutoCreates are columns that are not set only when a row is created.
+func (cc *ColumnCollection) NotAutoCreates() *ColumnCollection {
+	if cc.notAutoCreates != nil {
+		return cc.notAutoCreates
+	}
+
+	newCC := newColumnCollectionWithPrefix(cc.columnPrefix)
+
+	for _, c := range cc.columns {
+		if !c.IsAutoCreate {
+			newCC.Add(c)
+		}
+	}
+
+	cc.notAutoCreates = newCC
+	return cc.notAutoCreates
+}
+
+// NotVersions are columns that are not optimistic locking columns.
+func (cc *ColumnCollection) NotVersions() *ColumnCollection {
+	if cc.notVersions != nil {
+		return cc.notVersions
+	}
+
+	newCC := newColumnCollectionWithPrefix(cc.columnPrefix)
+
+	for _, c := range cc.columns {
+		if !c.IsVersion {
+			newCC.Add(c)
+		}
+	}
+
+	cc.notVersions = newCC
+	return cc.notVersions
+}
+
 // ReadOnly are columns that we don't have to insert upon Create().
 func (cc *ColumnCollection) ReadOnly() *ColumnCollection {
 	if cc.readOnly != nil {
@@ -360,22 +517,40 @@ func (cc ColumnCollection) ColumnNamesCSVFromAlias(tableAlias string) string {
 }
 
 // ColumnValues returns the reflected value for all the columns on a given instance.
+// The values of `json` columns that can't be marshalled are nil; writes read the values with `columnValues` to return the error instead.
 func (cc ColumnCollection) ColumnValues(instance interface{}) []interface{} {
+	values, _ := cc.columnValues(instance)
+	return values
+}
+
+// columnValues returns the reflected value for all the columns on a given instance,
+// and a `*JSONError` for the first `json` column that can't be marshalled.
+func (cc ColumnCollection) columnValues(instance interface{}) ([]interface{}, error) {
 	value := reflectValue(instance)
 
+	var err error
 	values := make([]interface{}, len(cc.columns))
 	for x := 0; x < len(cc.columns); x++ {
 		c := cc.columns[x]
-		valueField := value.FieldByName(c.FieldName)
+		valueField := c.fieldValue(value, false)
+		if !valueField.IsValid() {
+			continue
+		}
 		if c.IsJSON {
-			toSerialize := valueField.Interface()
-			jsonBytes, _ := json.Marshal(toSerialize)
-			values[x] = string(jsonBytes)
+			jsonValue, jsonErr := c.jsonValue(valueField)
+			if jsonErr != nil && err == nil {
+				err = jsonErr
+			}
+			values[x] = jsonValue
+		} else if c.IsArray {
+			values[x] = arrayValue(valueField.Interface())
+		} else if _, hasConverter := typeConverterFor(c.FieldType); hasConverter {
+			values[x] = convertedValue{value: valueField.Interface()}
 		} else {
 			values[x] = valueField.Interface()
 		}
 	}
-	return values
+	return values, err
 }
 
 // FirstOrDefault returns the first column in the collection or `nil` if the collection is empty.
@@ -1,6 +1,7 @@
 package spiffy
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/blendlabs/go-assert"
@@ -119,3 +120,104 @@ func TestColumnCollectionWriteColumns(t *testing.T) {
 	writeCols := meta.WriteColumns()
 	assert.NotZero(writeCols.Len())
 }
+
+func TestColumnCollectionVersions(t *testing.T) {
+	assert := assert.New(t)
+
+	meta := getCachedColumnCollectionFromInstance(versionedObj{})
+	assert.Equal(1, meta.Versions().Len())
+	assert.Equal("version", meta.Versions().FirstOrDefault().ColumnName)
+	assert.False(meta.NotVersions().HasColumn("version"))
+	assert.Equal(meta.Len()-1, meta.NotVersions().Len())
+}
+
+func TestColumnCollectionSoftDeletes(t *testing.T) {
+	assert := assert.New(t)
+
+	meta := getCachedColumnCollectionFromInstance(softDeleteObj{})
+	assert.Equal(1, meta.SoftDeletes().Len())
+	assert.Equal("deleted_utc", meta.SoftDeletes().FirstOrDefault().ColumnName)
+	assert.Zero(getCachedColumnCollectionFromInstance(benchObj{}).SoftDeletes().Len())
+}
+
+type auditFields struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type OwnerFields struct {
+	OwnerName string `db:"name"`
+}
+
+type ExportedAuditFields struct {
+	CreatedBy string `db:"created_by"`
+	internal  string
+}
+
+type embeddedObj struct {
+	ID int `db:"id,pk,serial"`
+	ExportedAuditFields
+	*OwnerFields `db:"owner_"`
+	Name         string `db:"name"`
+	Ignored      struct {
+		Value string `db:"value"`
+	} `db:"-"`
+}
+
+func (eo embeddedObj) TableName() string {
+	return "embedded_object"
+}
+
+type unexportedEmbeddedObj struct {
+	ID int `db:"id,pk,serial"`
+	auditFields
+}
+
+func (ueo unexportedEmbeddedObj) TableName() string {
+	return "embedded_object"
+}
+
+type ambiguousObj struct {
+	ExportedAuditFields
+	CreatedBy string `db:"created_by"`
+}
+
+func (ao ambiguousObj) TableName() string {
+	return "ambiguous_object"
+}
+
+func TestColumnCollectionEmbedded(t *testing.T) {
+	assert := assert.New(t)
+
+	meta := getCachedColumnCollectionFromInstance(embeddedObj{})
+	assert.Equal([]string{"id", "created_by", "owner_name", "name"}, meta.ColumnNames())
+
+	obj := embeddedObj{ID: 1, Name: "foo"}
+	obj.CreatedBy = "bar"
+	assert.Equal([]interface{}{1, "bar", nil, "foo"}, meta.ColumnValues(obj))
+
+	owner := meta.Lookup()["owner_name"]
+	assert.Nil(owner.GetValue(obj))
+	assert.Nil(owner.SetValue(&obj, "baz"))
+	assert.NotNil(obj.OwnerFields)
+	assert.Equal("baz", obj.OwnerName)
+	assert.Equal("baz", owner.GetValue(obj))
+	assert.NotNil(owner.SetValue(embeddedObj{}, "buzz"), "the object has to be a reference to allocate the embedded struct")
+
+	assert.Nil(meta.Lookup()["created_by"].SetValue(&obj, "created"))
+	assert.Equal("created", obj.CreatedBy)
+
+	// unexported embedded types can't be set through reflection, so they aren't mapped.
+	assert.Equal([]string{"id"}, getCachedColumnCollectionFromInstance(unexportedEmbeddedObj{}).ColumnNames())
+}
+
+func TestColumnCollectionEmbeddedConflict(t *testing.T) {
+	assert := assert.New(t)
+
+	meta := generateColumnCollectionForType(reflect.TypeOf(ambiguousObj{}))
+	assert.NotNil(meta.Err())
+	assert.Contains(meta.Err().Error(), "created_by")
+	assert.Nil(getCachedColumnCollectionFromInstance(embeddedObj{}).Err())
+
+	assert.NotNil(Validate(ambiguousObj{}))
+	assert.NotNil(Default().Create(&ambiguousObj{}), "the conflict is returned before anything is written")
+}
@@ -1,6 +1,9 @@
 package spiffy
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
 	"testing"
 
 	"github.com/blendlabs/go-assert"
@@ -42,6 +45,61 @@ func TestSetValueJSON(t *testing.T) {
 	a.Equal("bar", obj.JSONColumn.Foo)
 }
 
+type jsonObj struct {
+	ID       int               `db:"id,pk,serial"`
+	Settings *subStruct        `db:"settings,json"`
+	Labels   map[string]string `db:"labels,json"`
+	Invalid  chan int          `db:"invalid,json"`
+}
+
+func (jo jsonObj) TableName() string {
+	return "json_object"
+}
+
+func TestSetValueJSONBytes(t *testing.T) {
+	a := assert.New(t)
+	lookup := Columns(jsonObj{}).Lookup()
+	settings, labels := lookup["settings"], lookup["labels"]
+
+	var obj jsonObj
+	target := settings.scanTarget()
+	*(target.(*[]byte)) = []byte(`{"foo":"bar"}`)
+	a.Nil(settings.SetValue(&obj, target))
+	a.NotNil(obj.Settings)
+	a.Equal("bar", obj.Settings.Foo)
+
+	a.Nil(labels.SetValue(&obj, `{"a":"b"}`))
+	a.Equal(map[string]string{"a": "b"}, obj.Labels)
+	a.Nil(labels.SetValue(&obj, `{"c":"d"}`))
+	a.Equal(map[string]string{"c": "d"}, obj.Labels, "values replace rather than merge into the field")
+
+	a.Nil(settings.SetValue(&obj, settings.scanTarget()))
+	a.Nil(obj.Settings, "null values set the field to its zero value")
+
+	err := labels.SetValue(&obj, []byte(`{"a":`))
+	a.NotNil(err)
+	a.True(IsJSONError(err))
+	a.Equal("labels", err.(*JSONError).Column)
+	a.Equal("json_object", err.(*JSONError).Table)
+}
+
+func TestColumnValuesJSON(t *testing.T) {
+	a := assert.New(t)
+	cols := Columns(jsonObj{}).NotPrimaryKeys()
+
+	values, err := cols.columnValues(jsonObj{Labels: map[string]string{"a": "b"}})
+	a.NotNil(err)
+	a.True(IsJSONError(err))
+	a.Equal("invalid", err.(*JSONError).Column)
+	a.Nil(values[0], "nil references are written as null")
+	a.Equal(`{"a":"b"}`, values[1])
+
+	settings := newColumnCollectionFromColumns([]Column{*cols.Lookup()["settings"]})
+	values, err = settings.columnValues(jsonObj{Settings: &subStruct{Foo: "bar"}})
+	a.Nil(err)
+	a.Equal([]interface{}{`{"foo":"bar"}`}, values)
+}
+
 func TestSetValuePtr(t *testing.T) {
 	a := assert.New(t)
 	obj := myStruct{InferredName: "Hello."}
@@ -66,3 +124,85 @@ func TestGetValue(t *testing.T) {
 	a.NotNil(value)
 	a.Equal(5, value)
 }
+
+func TestNewColumnFromFieldTagOptions(t *testing.T) {
+	a := assert.New(t)
+
+	type tagged struct {
+		Status string `db:"status,required,maxlen=16,enum=pk_pending|serial_done"`
+		Name   string `db:"name,maxlen=not_a_number"`
+	}
+
+	col := NewColumnFromFieldTag(reflect.TypeOf(tagged{}).Field(0))
+	a.Equal("status", col.ColumnName)
+	a.True(col.IsRequired)
+	a.Equal(16, col.MaxLength)
+	a.Equal([]string{"pk_pending", "serial_done"}, col.Enum)
+	a.False(col.IsPrimaryKey, "option values should not be read as flags")
+	a.False(col.IsSerial, "option values should not be read as flags")
+
+	col = NewColumnFromFieldTag(reflect.TypeOf(tagged{}).Field(1))
+	a.Zero(col.MaxLength)
+	a.False(col.IsRequired)
+}
+
+type arrayObj struct {
+	ID     int      `db:"id,pk,serial"`
+	Tags   []string `db:"tags,array"`
+	Scores *[]int64 `db:"scores,array"`
+}
+
+func (ao arrayObj) TableName() string {
+	return "array_object"
+}
+
+func TestColumnArray(t *testing.T) {
+	a := assert.New(t)
+
+	lookup := Columns(arrayObj{}).Lookup()
+	tags, scores := lookup["tags"], lookup["scores"]
+	a.True(tags.IsArray)
+	a.True(scores.IsArray)
+	a.False(lookup["id"].IsArray)
+
+	var obj arrayObj
+	target := tags.scanTarget()
+	a.Nil(target.(sql.Scanner).Scan([]byte(`{"foo","bar"}`)))
+	a.Nil(tags.SetValue(&obj, target))
+	a.Equal([]string{"foo", "bar"}, obj.Tags)
+
+	target = scores.scanTarget()
+	a.Nil(target.(sql.Scanner).Scan([]byte(`{1,2}`)))
+	a.Nil(scores.SetValue(&obj, target))
+	a.NotNil(obj.Scores)
+	a.Equal([]int64{1, 2}, *obj.Scores)
+
+	a.Equal([]string{"foo", "bar"}, tags.GetValue(obj))
+
+	values := Columns(obj).ColumnValues(obj)
+	value, err := values[1].(driver.Valuer).Value()
+	a.Nil(err)
+	a.Equal(`{"foo","bar"}`, value)
+	value, err = values[2].(driver.Valuer).Value()
+	a.Nil(err)
+	a.Equal(`{1,2}`, value)
+
+	target = scores.scanTarget()
+	a.Nil(target.(sql.Scanner).Scan(nil))
+	a.Nil(scores.SetValue(&obj, target))
+	a.Nil(obj.Scores)
+	target = tags.scanTarget()
+	a.Nil(target.(sql.Scanner).Scan(nil))
+	a.Nil(tags.SetValue(&obj, target))
+	a.Nil(obj.Tags)
+
+	values = Columns(obj).ColumnValues(obj)
+	value, err = values[1].(driver.Valuer).Value()
+	a.Nil(err)
+	a.Nil(value)
+	a.Nil(values[2])
+
+	a.Nil(tags.SetValue(&obj, []string{"baz"}))
+	a.Equal([]string{"baz"}, obj.Tags)
+	a.NotNil(tags.SetValue(&obj, 1))
+}
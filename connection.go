@@ -4,6 +4,7 @@
 package spiffy
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/url"
@@ -147,8 +148,13 @@ type Connection struct {
 
 	useStatementCache bool
 	statementCache    *StatementCache
+
+	clock Clock
 }
 
+// Clock returns the current time.
+type Clock func() time.Time
+
 // Close implements a closer.
 func (dbc *Connection) Close() error {
 	var err error
@@ -182,6 +188,20 @@ func (dbc *Connection) fireEvent(flag logger.EventFlag, query string, elapsed ti
 	}
 }
 
+// SetClock sets the clock used for automatic timestamp columns, e.g. to pin the time in tests.
+// Setting it to nil restores the default, `time.Now`.
+func (dbc *Connection) SetClock(clock Clock) {
+	dbc.clock = clock
+}
+
+// Now returns the current time in UTC from the connection's clock.
+func (dbc *Connection) Now() time.Time {
+	if dbc.clock != nil {
+		return dbc.clock().UTC()
+	}
+	return time.Now().UTC()
+}
+
 // EnableStatementCache opts to cache statements for the connection.
 func (dbc *Connection) EnableStatementCache() {
 	dbc.useStatementCache = true
@@ -267,23 +287,50 @@ func (dbc *Connection) Open() (*sql.DB, error) {
 
 // Begin starts a new transaction.
 func (dbc *Connection) Begin() (*sql.Tx, error) {
-	if dbc.Connection != nil {
-		tx, txErr := dbc.Connection.Begin()
-		return tx, exception.Wrap(txErr)
-	}
+	return dbc.BeginTx(context.Background(), nil)
+}
+
+// BeginContext starts a new transaction bound to a context.
+// If the context is canceled before the transaction is committed the transaction is rolled back.
+func (dbc *Connection) BeginContext(ctx context.Context) (*sql.Tx, error) {
+	return dbc.BeginTx(ctx, nil)
+}
 
+// BeginWithOptions starts a new transaction with a given isolation level, read only and deferrable flags.
+func (dbc *Connection) BeginWithOptions(opts *TxOptions) (*sql.Tx, error) {
+	return dbc.BeginTx(context.Background(), opts)
+}
+
+// BeginTx starts a new transaction bound to a context with a given set of options.
+// `opts` can be nil, in which case the server defaults are used.
+func (dbc *Connection) BeginTx(ctx context.Context, opts *TxOptions) (*sql.Tx, error) {
 	connection, err := dbc.Open()
 	if err != nil {
 		return nil, exception.Wrap(err)
 	}
-	tx, err := connection.Begin()
-	return tx, exception.Wrap(err)
+
+	tx, err := connection.BeginTx(ctx, opts.sqlTxOptions())
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+
+	if opts != nil && opts.Deferrable {
+		if _, err = tx.ExecContext(ctx, "SET TRANSACTION DEFERRABLE"); err != nil {
+			return nil, exception.Nest(err, tx.Rollback())
+		}
+	}
+	return tx, nil
 }
 
 // Prepare prepares a new statement for the connection.
 func (dbc *Connection) Prepare(statement string, tx *sql.Tx) (*sql.Stmt, error) {
+	return dbc.PrepareContext(context.Background(), statement, tx)
+}
+
+// PrepareContext prepares a new statement for the connection with a context.
+func (dbc *Connection) PrepareContext(ctx context.Context, statement string, tx *sql.Tx) (*sql.Stmt, error) {
 	if tx != nil {
-		stmt, err := tx.Prepare(statement)
+		stmt, err := tx.PrepareContext(ctx, statement)
 		if err != nil {
 			return nil, exception.Wrap(err)
 		}
@@ -296,7 +343,7 @@ func (dbc *Connection) Prepare(statement string, tx *sql.Tx) (*sql.Stmt, error)
 		return nil, exception.Wrap(err)
 	}
 
-	stmt, err := dbConn.Prepare(statement)
+	stmt, err := dbConn.PrepareContext(ctx, statement)
 	if err != nil {
 		return nil, exception.Wrap(err)
 	}
@@ -320,8 +367,13 @@ func (dbc *Connection) ensureStatementCache() error {
 
 // PrepareCached prepares a potentially cached statement.
 func (dbc *Connection) PrepareCached(id, statement string, tx *sql.Tx) (*sql.Stmt, error) {
+	return dbc.PrepareCachedContext(context.Background(), id, statement, tx)
+}
+
+// PrepareCachedContext prepares a potentially cached statement with a context.
+func (dbc *Connection) PrepareCachedContext(ctx context.Context, id, statement string, tx *sql.Tx) (*sql.Stmt, error) {
 	if tx != nil {
-		stmt, err := tx.Prepare(statement)
+		stmt, err := tx.PrepareContext(ctx, statement)
 		if err != nil {
 			return nil, exception.Wrap(err)
 		}
@@ -330,9 +382,9 @@ func (dbc *Connection) PrepareCached(id, statement string, tx *sql.Tx) (*sql.Stm
 
 	if dbc.useStatementCache {
 		dbc.ensureStatementCache()
-		return dbc.statementCache.Prepare(id, statement)
+		return dbc.statementCache.PrepareContext(ctx, id, statement)
 	}
-	return dbc.Prepare(statement, tx)
+	return dbc.PrepareContext(ctx, statement, tx)
 }
 
 // --------------------------------------------------------------------------------
@@ -354,6 +406,11 @@ func (dbc *Connection) InTx(txs ...*sql.Tx) *DB {
 	return &DB{conn: dbc, tx: OptionalTx(txs...)}
 }
 
+// InTxWithOptions is a shortcut for DB().InTxWithOptions(...).
+func (dbc *Connection) InTxWithOptions(opts *TxOptions) *DB {
+	return dbc.DB().InTxWithOptions(opts)
+}
+
 // --------------------------------------------------------------------------------
 // Invocation Context Stubs
 // --------------------------------------------------------------------------------
@@ -408,6 +465,26 @@ func (dbc *Connection) GetAllInTx(collection interface{}, tx *sql.Tx) error {
 	return dbc.DB().InTx(tx).Invoke().GetAll(collection)
 }
 
+// Preload loads the named relation fields of a collection of objects.
+func (dbc *Connection) Preload(collection interface{}, relations ...string) error {
+	return dbc.PreloadInTx(collection, nil, relations...)
+}
+
+// PreloadInTx loads the named relation fields of a collection of objects wrapped in a transaction.
+func (dbc *Connection) PreloadInTx(collection interface{}, tx *sql.Tx, relations ...string) error {
+	return dbc.DB().InTx(tx).Invoke().Preload(collection, relations...)
+}
+
+// GetWhere returns the rows of an object mapped table that match a where clause.
+func (dbc *Connection) GetWhere(collection interface{}, where string, args ...interface{}) error {
+	return dbc.GetWhereInTx(collection, nil, where, args...)
+}
+
+// GetWhereInTx returns the rows of an object mapped table that match a where clause within a transaction.
+func (dbc *Connection) GetWhereInTx(collection interface{}, tx *sql.Tx, where string, args ...interface{}) error {
+	return dbc.DB().InTx(tx).Invoke().GetWhere(collection, where, args...)
+}
+
 // Create writes an object to the database.
 func (dbc *Connection) Create(object DatabaseMapped) error {
 	return dbc.CreateInTx(object, nil)
@@ -438,6 +515,16 @@ func (dbc *Connection) CreateManyInTx(objects interface{}, tx *sql.Tx) (err erro
 	return dbc.DB().InTx(tx).Invoke().CreateMany(objects)
 }
 
+// CopyMany writes many objects to the database using `COPY`.
+func (dbc *Connection) CopyMany(objects interface{}) error {
+	return dbc.CopyManyInTx(objects, nil)
+}
+
+// CopyManyInTx writes many objects to the database using `COPY` within a transaction.
+func (dbc *Connection) CopyManyInTx(objects interface{}, tx *sql.Tx) (err error) {
+	return dbc.DB().InTx(tx).Invoke().CopyMany(objects)
+}
+
 // Update updates an object.
 func (dbc *Connection) Update(object DatabaseMapped) error {
 	return dbc.UpdateInTx(object, nil)
@@ -448,6 +535,16 @@ func (dbc *Connection) UpdateInTx(object DatabaseMapped, tx *sql.Tx) (err error)
 	return dbc.DB().InTx(tx).Invoke().Update(object)
 }
 
+// UpdateWhere updates every row matching a where clause with the values of an object.
+func (dbc *Connection) UpdateWhere(object DatabaseMapped, where string, args ...interface{}) (int64, error) {
+	return dbc.UpdateWhereInTx(object, nil, where, args...)
+}
+
+// UpdateWhereInTx updates every row matching a where clause with the values of an object within a transaction.
+func (dbc *Connection) UpdateWhereInTx(object DatabaseMapped, tx *sql.Tx, where string, args ...interface{}) (int64, error) {
+	return dbc.DB().InTx(tx).Invoke().UpdateWhere(object, where, args...)
+}
+
 // Exists returns a bool if a given object exists (utilizing the primary key columns if they exist).
 func (dbc *Connection) Exists(object DatabaseMapped) (bool, error) {
 	return dbc.ExistsInTx(object, nil)
@@ -468,12 +565,52 @@ func (dbc *Connection) DeleteInTx(object DatabaseMapped, tx *sql.Tx) (err error)
 	return dbc.DB().InTx(tx).Invoke().Delete(object)
 }
 
+// DeleteWhere deletes the rows of an object mapped table that match a where clause.
+func (dbc *Connection) DeleteWhere(object DatabaseMapped, where string, args ...interface{}) (int64, error) {
+	return dbc.DeleteWhereInTx(object, nil, where, args...)
+}
+
+// DeleteWhereInTx deletes the rows of an object mapped table that match a where clause within a transaction.
+func (dbc *Connection) DeleteWhereInTx(object DatabaseMapped, tx *sql.Tx, where string, args ...interface{}) (int64, error) {
+	return dbc.DB().InTx(tx).Invoke().DeleteWhere(object, where, args...)
+}
+
+// UpdateColumns updates only the named columns of an object.
+func (dbc *Connection) UpdateColumns(object DatabaseMapped, columnNames ...string) error {
+	return dbc.UpdateColumnsInTx(object, nil, columnNames...)
+}
+
+// UpdateColumnsInTx updates only the named columns of an object wrapped in a transaction.
+func (dbc *Connection) UpdateColumnsInTx(object DatabaseMapped, tx *sql.Tx, columnNames ...string) (err error) {
+	return dbc.DB().InTx(tx).Invoke().UpdateColumns(object, columnNames...)
+}
+
+// UpdateChanged updates only the columns of a change tracked object that have changed since it was read.
+func (dbc *Connection) UpdateChanged(object DatabaseMapped) error {
+	return dbc.UpdateChangedInTx(object, nil)
+}
+
+// UpdateChangedInTx updates only the columns of a change tracked object that have changed since it was read wrapped in a transaction.
+func (dbc *Connection) UpdateChangedInTx(object DatabaseMapped, tx *sql.Tx) (err error) {
+	return dbc.DB().InTx(tx).Invoke().UpdateChanged(object)
+}
+
 // Upsert inserts the object if it doesn't exist already (as defined by its primary keys) or updates it.
-func (dbc *Connection) Upsert(object DatabaseMapped) error {
-	return dbc.UpsertInTx(object, nil)
+func (dbc *Connection) Upsert(object DatabaseMapped, opts ...UpsertOptions) error {
+	return dbc.UpsertInTx(object, nil, opts...)
 }
 
 // UpsertInTx inserts the object if it doesn't exist already (as defined by its primary keys) or updates it wrapped in a transaction.
-func (dbc *Connection) UpsertInTx(object DatabaseMapped, tx *sql.Tx) (err error) {
-	return dbc.DB().InTx(tx).Invoke().Upsert(object)
+func (dbc *Connection) UpsertInTx(object DatabaseMapped, tx *sql.Tx, opts ...UpsertOptions) (err error) {
+	return dbc.DB().InTx(tx).Invoke().Upsert(object, opts...)
+}
+
+// UpsertMany inserts or updates many objects.
+func (dbc *Connection) UpsertMany(objects interface{}, opts ...UpsertOptions) error {
+	return dbc.UpsertManyInTx(objects, nil, opts...)
+}
+
+// UpsertManyInTx inserts or updates many objects wrapped in a transaction.
+func (dbc *Connection) UpsertManyInTx(objects interface{}, tx *sql.Tx, opts ...UpsertOptions) (err error) {
+	return dbc.DB().InTx(tx).Invoke().UpsertMany(objects, opts...)
 }
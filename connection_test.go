@@ -319,6 +319,68 @@ func TestConnectionCreateParallel(t *testing.T) {
 	wg.Wait()
 }
 
+func TestConnectionUpdateColumns(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createTable(tx))
+
+	obj := &benchObj{Name: "test_object", Timestamp: time.Now().UTC(), Category: "category_0"}
+	assert.Nil(Default().CreateInTx(obj, tx))
+
+	obj.Name = "updated"
+	obj.Category = "not written"
+	assert.Nil(Default().UpdateColumnsInTx(obj, tx, "name"))
+
+	var verify benchObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal("updated", verify.Name)
+	assert.Equal("category_0", verify.Category)
+
+	assert.NotNil(Default().UpdateColumnsInTx(obj, tx, "not_a_column"))
+	assert.NotNil(Default().UpdateColumnsInTx(obj, tx, "id"))
+	assert.NotNil(Default().UpdateColumnsInTx(obj, tx))
+}
+
+func TestConnectionUpdateChanged(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createTable(tx))
+
+	obj := &trackedObj{Name: "test_object", Timestamp: time.Now().UTC(), Category: "category_0"}
+	assert.Nil(Default().CreateInTx(obj, tx))
+
+	var first, second trackedObj
+	assert.Nil(Default().GetByIDInTx(&first, tx, obj.ID))
+	assert.Nil(Default().GetByIDInTx(&second, tx, obj.ID))
+
+	first.Name = "updated by first"
+	second.Category = "updated by second"
+	assert.Nil(Default().UpdateChangedInTx(&first, tx))
+	assert.Nil(Default().UpdateChangedInTx(&second, tx))
+
+	var verify trackedObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal("updated by first", verify.Name)
+	assert.Equal("updated by second", verify.Category)
+
+	// nothing has changed, so nothing is written.
+	assert.Nil(Default().ExecInTx("update bench_object set name = 'changed underneath' where id = $1", tx, obj.ID))
+	assert.Nil(Default().UpdateChangedInTx(&first, tx))
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal("changed underneath", verify.Name)
+
+	var all []trackedObj
+	assert.Nil(Default().GetAllInTx(&all, tx))
+	assert.NotEmpty(all)
+	assert.True(all[0].IsTracking())
+}
+
 func TestConnectionUpsert(t *testing.T) {
 	assert := assert.New(t)
 	tx, err := Default().Begin()
@@ -351,6 +413,88 @@ func TestConnectionUpsert(t *testing.T) {
 	assert.Equal(obj.Category, verify.Category)
 }
 
+func TestConnectionUpsertMany(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	err = createUpserObjectTable(tx)
+	assert.Nil(err)
+
+	var objects []upsertObj
+	for x := 0; x < 5; x++ {
+		objects = append(objects, upsertObj{UUID: UUIDv4().ToShortString(), Timestamp: time.Now().UTC(), Category: "original"})
+	}
+	assert.Nil(Default().UpsertManyInTx(objects, tx))
+
+	for x := range objects {
+		objects[x].Category = "updated"
+		objects[x].Timestamp = time.Date(2017, 01, 01, 0, 0, 0, 0, time.UTC)
+	}
+	objects = append(objects, upsertObj{UUID: UUIDv4().ToShortString(), Timestamp: time.Now().UTC(), Category: "updated"})
+
+	assert.Nil(Default().UpsertManyInTx(objects, tx, UpsertOptions{UpdateColumns: []string{"category"}}))
+
+	var verify upsertObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, objects[0].UUID))
+	assert.Equal("updated", verify.Category)
+	assert.NotEqual(2017, verify.Timestamp.Year(), "only the category should have been updated")
+
+	objects[0].Category = "skipped"
+	assert.Nil(Default().UpsertManyInTx(objects[:1], tx, UpsertOptions{DoNothing: true}))
+	assert.Nil(Default().GetByIDInTx(&verify, tx, objects[0].UUID))
+	assert.Equal("updated", verify.Category)
+
+	assert.Nil(Default().UpsertInTx(&objects[0], tx, UpsertOptions{Where: "upsert_object.category <> $1", WhereArgs: []interface{}{"updated"}}))
+	assert.Nil(Default().GetByIDInTx(&verify, tx, objects[0].UUID))
+	assert.Equal("updated", verify.Category)
+
+	assert.Nil(Default().UpsertInTx(&objects[0], tx, UpsertOptions{Where: "upsert_object.category = $1", WhereArgs: []interface{}{"updated"}}))
+	assert.Nil(Default().GetByIDInTx(&verify, tx, objects[0].UUID))
+	assert.Equal("skipped", verify.Category)
+}
+
+func TestConnectionUpsertManyVersioned(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createVersionedObjectTable(tx))
+
+	objects := []versionedObj{{Name: "foo", Version: 1}, {Name: "bar", Version: 1}}
+	assert.Nil(Default().UpsertManyInTx(objects, tx))
+	for _, obj := range objects {
+		assert.NotZero(obj.ID)
+		assert.Equal(int64(1), obj.Version)
+	}
+}
+
+func TestConnectionUpsertManyChunkedRollsBack(t *testing.T) {
+	assert := assert.New(t)
+
+	// this has to run outside a transaction, so the table is committed and the rows are cleaned up after.
+	assert.Nil(Default().Exec(`CREATE TABLE IF NOT EXISTS upsert_object (uuid varchar(255) primary key, timestamp_utc timestamp, category varchar(255));`))
+	category := UUIDv4().ToShortString()
+	defer Default().Exec(`DELETE FROM upsert_object WHERE category = $1`, category)
+
+	// three write columns per object puts this over the parameter limit, and upserting the same key twice
+	// in one statement fails the last chunk.
+	count := (maxStatementParameters / 3) + 10
+	objects := make([]upsertObj, count)
+	for x := 0; x < count; x++ {
+		objects[x] = upsertObj{UUID: UUIDv4().ToShortString(), Timestamp: time.Now().UTC(), Category: category}
+	}
+	objects[count-1].UUID = objects[count-2].UUID
+
+	assert.NotNil(Default().UpsertMany(objects))
+
+	var verify int
+	assert.Nil(Default().Query(`select count(*) from upsert_object where category = $1`, category).Scan(&verify))
+	assert.Zero(verify)
+}
+
 func TestConnectionUpsertWithSerial(t *testing.T) {
 	assert := assert.New(t)
 	tx, err := Default().Begin()
@@ -416,6 +560,125 @@ func TestConnectionCreateMany(t *testing.T) {
 	assert.NotEmpty(verify)
 }
 
+func TestConnectionCreateManySetsSerials(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	err = createTable(tx)
+	assert.Nil(err)
+
+	objects := make([]benchObj, 10)
+	pointers := make([]*benchObj, 10)
+	for x := 0; x < 10; x++ {
+		objects[x] = benchObj{Name: fmt.Sprintf("test_object_%d", x), Timestamp: time.Now().UTC(), Category: "create_many_serials"}
+		pointers[x] = &benchObj{Name: fmt.Sprintf("test_pointer_%d", x), Timestamp: time.Now().UTC(), Category: "create_many_serials"}
+	}
+
+	assert.Nil(Default().CreateManyInTx(objects, tx))
+	assert.Nil(Default().CreateManyInTx(pointers, tx))
+
+	for x := 0; x < 10; x++ {
+		assert.NotZero(objects[x].ID)
+		assert.NotZero(pointers[x].ID)
+
+		var verify benchObj
+		assert.Nil(Default().GetByIDInTx(&verify, tx, objects[x].ID))
+		assert.Equal(objects[x].Name, verify.Name)
+
+		assert.Nil(Default().GetByIDInTx(&verify, tx, pointers[x].ID))
+		assert.Equal(pointers[x].Name, verify.Name)
+	}
+}
+
+func TestConnectionCreateManyChunked(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	err = createTable(tx)
+	assert.Nil(err)
+
+	// five write columns per object puts this over the parameter limit for a single statement.
+	count := (maxStatementParameters / 5) + 10
+	objects := make([]benchObj, count)
+	for x := 0; x < count; x++ {
+		objects[x] = benchObj{
+			Name:      fmt.Sprintf("test_object_%d", x),
+			Timestamp: time.Now().UTC(),
+			Category:  "create_many_chunked",
+		}
+	}
+
+	err = Default().CreateManyInTx(objects, tx)
+	assert.Nil(err)
+
+	var verify int
+	err = Default().QueryInTx(`select count(*) from bench_object where category = $1`, tx, "create_many_chunked").Scan(&verify)
+	assert.Nil(err)
+	assert.Equal(count, verify)
+}
+
+func TestConnectionCreateManyChunkedRollsBack(t *testing.T) {
+	assert := assert.New(t)
+
+	// this has to run outside a transaction, so the table is committed and the rows are cleaned up after.
+	assert.Nil(Default().Exec(`CREATE TABLE IF NOT EXISTS upsert_object (uuid varchar(255) primary key, timestamp_utc timestamp, category varchar(255));`))
+	category := UUIDv4().ToShortString()
+	defer Default().Exec(`DELETE FROM upsert_object WHERE category = $1`, category)
+
+	// three write columns per object puts this over the parameter limit, and the duplicate key fails the last chunk.
+	count := (maxStatementParameters / 3) + 10
+	objects := make([]upsertObj, count)
+	for x := 0; x < count; x++ {
+		objects[x] = upsertObj{UUID: UUIDv4().ToShortString(), Timestamp: time.Now().UTC(), Category: category}
+	}
+	objects[count-1].UUID = objects[0].UUID
+
+	assert.NotNil(Default().CreateMany(objects))
+
+	var verify int
+	assert.Nil(Default().Query(`select count(*) from upsert_object where category = $1`, category).Scan(&verify))
+	assert.Zero(verify)
+}
+
+func TestConnectionCopyMany(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	err = createTable(tx)
+	assert.Nil(err)
+
+	var objects []benchObj
+	for x := 0; x < 100; x++ {
+		objects = append(objects, benchObj{
+			Name:      fmt.Sprintf("test_object_%d", x),
+			Timestamp: time.Now().UTC(),
+			Amount:    1005.0,
+			Pending:   x%2 == 0,
+			Category:  "copy_many",
+		})
+	}
+
+	err = Default().CopyManyInTx(objects, tx)
+	assert.Nil(err)
+
+	var verify []benchObj
+	err = Default().QueryInTx(`select * from bench_object where category = $1 order by id`, tx, "copy_many").OutMany(&verify)
+	assert.Nil(err)
+	assert.Len(verify, len(objects))
+	assert.Equal("test_object_0", verify[0].Name)
+	assert.True(verify[0].Pending)
+	assert.False(verify[1].Pending)
+	assert.NotZero(verify[0].ID)
+
+	assert.Nil(Default().CopyManyInTx([]benchObj{}, tx))
+}
+
 func TestConnectionCreateIfNotExists(t *testing.T) {
 	assert := assert.New(t)
 	tx, err := Default().Begin()
@@ -493,3 +756,378 @@ func TestConnectionInvalidatesBadCachedStatements(t *testing.T) {
 	_, err = conn.Query(queryStatement).Any()
 	assert.Nil(err)
 }
+
+func TestConnectionBeginWithOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	tx, err := Default().BeginWithOptions(&TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true, Deferrable: true})
+	assert.Nil(err)
+	assert.NotNil(tx)
+	defer tx.Rollback()
+
+	var isolation, readOnly, deferrable string
+	err = Default().QueryInTx("SHOW transaction_isolation", tx).Scan(&isolation)
+	assert.Nil(err)
+	assert.Equal("serializable", isolation)
+
+	err = Default().QueryInTx("SHOW transaction_read_only", tx).Scan(&readOnly)
+	assert.Nil(err)
+	assert.Equal("on", readOnly)
+
+	err = Default().QueryInTx("SHOW transaction_deferrable", tx).Scan(&deferrable)
+	assert.Nil(err)
+	assert.Equal("on", deferrable)
+}
+
+func TestConnectionWhereMethods(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(seedObjects(10, tx))
+
+	var matched []benchObj
+	err = Default().GetWhereInTx(&matched, tx, "category = $1 or category = $2", "category_3", "category_4")
+	assert.Nil(err)
+	assert.Len(matched, 2)
+
+	template := benchObj{Name: "updated", Timestamp: time.Now().UTC(), Amount: 1.0, Pending: true, Category: "updated_category"}
+	updated, err := Default().UpdateWhereInTx(&template, tx, "category = $1 or category = $2", "category_3", "category_4")
+	assert.Nil(err)
+	assert.Equal(int64(2), updated)
+
+	var verify []benchObj
+	err = Default().GetWhereInTx(&verify, tx, "category = $1", "updated_category")
+	assert.Nil(err)
+	assert.Len(verify, 2)
+
+	deleted, err := Default().DeleteWhereInTx(benchObj{}, tx, "category = $1", "updated_category")
+	assert.Nil(err)
+	assert.Equal(int64(2), deleted)
+
+	_, err = Default().DeleteWhereInTx(benchObj{}, tx, "")
+	assert.NotNil(err)
+}
+
+type versionedObj struct {
+	ID      int    `db:"id,pk,serial"`
+	Name    string `db:"name"`
+	Version int64  `db:"version,version"`
+}
+
+func (vo versionedObj) TableName() string {
+	return "versioned_object"
+}
+
+func createVersionedObjectTable(tx *sql.Tx) error {
+	createSQL := `CREATE TABLE IF NOT EXISTS versioned_object (id serial not null primary key, name varchar(255), version bigint not null default 0);`
+	return Default().ExecInTx(createSQL, tx)
+}
+
+func TestConnectionUpdateVersioned(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createVersionedObjectTable(tx))
+
+	obj := &versionedObj{Name: "test", Version: 1}
+	assert.Nil(Default().CreateInTx(obj, tx))
+
+	var stale versionedObj
+	assert.Nil(Default().GetByIDInTx(&stale, tx, obj.ID))
+
+	obj.Name = "updated"
+	assert.Nil(Default().UpdateInTx(obj, tx))
+	assert.Equal(int64(2), obj.Version)
+
+	stale.Name = "stale"
+	err = Default().UpdateInTx(&stale, tx)
+	assert.True(IsVersionConflict(err))
+	assert.Equal(int64(1), stale.Version)
+
+	var verify versionedObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal("updated", verify.Name)
+	assert.Equal(int64(2), verify.Version)
+
+	assert.Nil(Default().UpsertInTx(obj, tx))
+	assert.Equal(int64(3), obj.Version)
+
+	err = Default().UpsertInTx(&stale, tx)
+	assert.True(IsVersionConflict(err))
+}
+
+func TestConnectionUpdateWhereVersioned(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createVersionedObjectTable(tx))
+
+	first := &versionedObj{Name: "first", Version: 1}
+	assert.Nil(Default().CreateInTx(first, tx))
+	second := &versionedObj{Name: "second", Version: 5}
+	assert.Nil(Default().CreateInTx(second, tx))
+
+	template := versionedObj{Name: "renamed", Version: 100}
+	updated, err := Default().UpdateWhereInTx(&template, tx, "id = $1 or id = $2", first.ID, second.ID)
+	assert.Nil(err)
+	assert.Equal(int64(2), updated)
+
+	var verify versionedObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, first.ID))
+	assert.Equal("renamed", verify.Name)
+	assert.Equal(int64(2), verify.Version)
+	assert.Nil(Default().GetByIDInTx(&verify, tx, second.ID))
+	assert.Equal(int64(6), verify.Version)
+
+	first.Name = "stale"
+	assert.True(IsVersionConflict(Default().UpdateInTx(first, tx)))
+}
+
+type softDeleteObj struct {
+	ID         int        `db:"id,pk,serial"`
+	Name       string     `db:"name"`
+	DeletedUTC *time.Time `db:"deleted_utc,softdelete"`
+}
+
+func (sdo softDeleteObj) TableName() string {
+	return "soft_delete_object"
+}
+
+func createSoftDeleteObjectTable(tx *sql.Tx) error {
+	createSQL := `CREATE TABLE IF NOT EXISTS soft_delete_object (id serial not null primary key, name varchar(255), deleted_utc timestamp);`
+	return Default().ExecInTx(createSQL, tx)
+}
+
+func TestConnectionSoftDelete(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createSoftDeleteObjectTable(tx))
+
+	kept := &softDeleteObj{Name: "kept"}
+	deleted := &softDeleteObj{Name: "deleted"}
+	assert.Nil(Default().CreateInTx(kept, tx))
+	assert.Nil(Default().CreateInTx(deleted, tx))
+
+	assert.Nil(Default().DeleteInTx(deleted, tx))
+	assert.NotNil(deleted.DeletedUTC)
+
+	var verify softDeleteObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, deleted.ID))
+	assert.Zero(verify.ID)
+
+	exists, err := Default().ExistsInTx(deleted, tx)
+	assert.Nil(err)
+	assert.False(exists)
+
+	var all []softDeleteObj
+	assert.Nil(Default().GetAllInTx(&all, tx))
+	assert.Len(all, 1)
+	assert.Equal("kept", all[0].Name)
+
+	// updating the deleted row leaves its soft delete column set.
+	rowsAffected, err := Default().UpdateWhereInTx(&softDeleteObj{Name: "renamed"}, tx, "id = $1", deleted.ID)
+	assert.Nil(err)
+	assert.Equal(int64(1), rowsAffected)
+
+	var allUnscoped []softDeleteObj
+	assert.Nil(Default().DB().InTx(tx).Invoke().Unscoped().Get(&verify, deleted.ID))
+	assert.Equal(deleted.ID, verify.ID)
+	assert.Equal("renamed", verify.Name)
+	assert.NotNil(verify.DeletedUTC)
+
+	assert.Nil(Default().DB().InTx(tx).Invoke().Unscoped().GetAll(&allUnscoped))
+	assert.Len(allUnscoped, 2)
+
+	rowsAffected, err = Default().DeleteWhereInTx(softDeleteObj{}, tx, "name = $1", "kept")
+	assert.Nil(err)
+	assert.Equal(int64(1), rowsAffected)
+	var remaining []softDeleteObj
+	assert.Nil(Default().GetWhereInTx(&remaining, tx, "name = $1", "kept"))
+	assert.Empty(remaining)
+
+	assert.Nil(Default().DB().InTx(tx).Invoke().HardDelete().Delete(deleted))
+	var count int
+	assert.Nil(Default().QueryInTx("select count(*) from soft_delete_object", tx).Scan(&count))
+	assert.Equal(1, count)
+}
+
+func createTimestampedObjectTable(tx *sql.Tx) error {
+	createSQL := `CREATE TABLE IF NOT EXISTS timestamped_object (id serial not null primary key, name varchar(255), created_utc timestamp not null, updated_utc timestamp);`
+	return Default().ExecInTx(createSQL, tx)
+}
+
+func TestConnectionAutoTimestamps(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	now := time.Date(2017, 02, 03, 04, 05, 06, 0, time.UTC)
+	Default().SetClock(func() time.Time { return now })
+	defer Default().SetClock(nil)
+
+	assert.Nil(createTimestampedObjectTable(tx))
+
+	obj := &timestampedObj{Name: "foo"}
+	assert.Nil(Default().CreateInTx(obj, tx))
+	assert.Equal(now, obj.CreatedUTC)
+	assert.Equal(now, *obj.UpdatedUTC)
+
+	now = now.Add(time.Hour)
+	obj.Name = "bar"
+	assert.Nil(Default().UpdateColumnsInTx(obj, tx, "name"))
+	assert.Equal(now, *obj.UpdatedUTC)
+
+	var verify timestampedObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal(now.Add(-time.Hour), verify.CreatedUTC.UTC())
+	assert.Equal(now, verify.UpdatedUTC.UTC())
+
+	many := []timestampedObj{{Name: "many_0"}, {Name: "many_1"}}
+	assert.Nil(Default().CreateManyInTx(many, tx))
+	assert.Equal(now, many[1].CreatedUTC)
+
+	now = now.Add(time.Hour)
+	assert.Nil(Default().UpsertInTx(&verify, tx))
+	assert.Equal(now, verify.UpdatedUTC.UTC())
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal(obj.CreatedUTC, verify.CreatedUTC.UTC())
+}
+
+func createEmbeddedObjectTable(tx *sql.Tx) error {
+	createSQL := `CREATE TABLE IF NOT EXISTS embedded_object (id serial not null primary key, created_by varchar(255), owner_name varchar(255), name varchar(255));`
+	return Default().ExecInTx(createSQL, tx)
+}
+
+func TestConnectionEmbeddedStructs(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createEmbeddedObjectTable(tx))
+
+	obj := &embeddedObj{Name: "foo", OwnerFields: &OwnerFields{OwnerName: "owner"}}
+	obj.CreatedBy = "creator"
+	assert.Nil(Default().CreateInTx(obj, tx))
+	assert.NotZero(obj.ID)
+
+	var verify embeddedObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal("foo", verify.Name)
+	assert.Equal("creator", verify.CreatedBy)
+	assert.NotNil(verify.OwnerFields)
+	assert.Equal("owner", verify.OwnerName)
+
+	unowned := &embeddedObj{Name: "bar"}
+	assert.Nil(Default().CreateInTx(unowned, tx))
+
+	var all []embeddedObj
+	assert.Nil(Default().GetAllInTx(&all, tx))
+	assert.Len(all, 2)
+}
+
+func TestConnectionTypeConverter(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	registerPointConverter()
+	defer UnregisterTypeConverter(point{})
+
+	assert.Nil(Default().ExecInTx(`CREATE TABLE IF NOT EXISTS converted_object (id serial not null primary key, location point not null, previous point);`, tx))
+
+	obj := &convertedObj{Location: point{X: 1, Y: 2}}
+	assert.Nil(Default().CreateInTx(obj, tx))
+
+	var verify convertedObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal(point{X: 1, Y: 2}, verify.Location)
+	assert.Nil(verify.Previous)
+
+	verify.Previous = &point{X: 3, Y: 4}
+	assert.Nil(Default().UpdateInTx(&verify, tx))
+
+	var all []convertedObj
+	assert.Nil(Default().QueryInTx("select * from converted_object", tx).OutMany(&all))
+	assert.Len(all, 1)
+	assert.Equal(point{X: 3, Y: 4}, *all[0].Previous)
+}
+
+func TestConnectionArrayColumns(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(Default().ExecInTx(`CREATE TABLE IF NOT EXISTS array_object (id serial not null primary key, tags varchar(255)[], scores bigint[]);`, tx))
+
+	scores := []int64{1, 2, 3}
+	obj := &arrayObj{Tags: []string{"foo", "bar"}, Scores: &scores}
+	assert.Nil(Default().CreateInTx(obj, tx))
+	empty := &arrayObj{}
+	assert.Nil(Default().CreateInTx(empty, tx))
+
+	var verify arrayObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal([]string{"foo", "bar"}, verify.Tags)
+	assert.Equal(scores, *verify.Scores)
+
+	var all []arrayObj
+	assert.Nil(Default().QueryInTx("select * from array_object where id = $1", tx, empty.ID).OutMany(&all))
+	assert.Len(all, 1)
+	assert.Nil(all[0].Tags)
+	assert.Nil(all[0].Scores)
+}
+
+type jsonbObj struct {
+	ID       int               `db:"id,pk,serial"`
+	Settings *subStruct        `db:"settings,json"`
+	Labels   map[string]string `db:"labels,json"`
+}
+
+func (jo jsonbObj) TableName() string {
+	return "jsonb_object"
+}
+
+func TestConnectionJSONColumns(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(Default().ExecInTx(`CREATE TABLE IF NOT EXISTS jsonb_object (id serial not null primary key, settings jsonb, labels json);`, tx))
+
+	objs := []jsonbObj{
+		{Settings: &subStruct{Foo: "bar"}, Labels: map[string]string{"a": "b"}},
+		{},
+	}
+	assert.Nil(Default().CreateManyInTx(objs, tx))
+
+	var verify jsonbObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, objs[0].ID))
+	assert.Equal("bar", verify.Settings.Foo)
+	assert.Equal(map[string]string{"a": "b"}, verify.Labels)
+
+	var nulls int
+	assert.Nil(Default().QueryInTx("select count(*) from jsonb_object where id = $1 and settings is null and labels is null", tx, objs[1].ID).Scan(&nulls))
+	assert.Equal(1, nulls)
+
+	verify.Settings = nil
+	assert.Nil(Default().UpdateInTx(&verify, tx))
+	assert.Nil(Default().GetByIDInTx(&verify, tx, objs[0].ID))
+	assert.Nil(verify.Settings)
+
+	err = Default().CreateInTx(&jsonObj{}, tx)
+	assert.True(IsJSONError(err))
+}
@@ -1,9 +1,11 @@
 package spiffy
 
 import (
+	"context"
 	"database/sql"
 
 	exception "github.com/blendlabs/go-exception"
+	"github.com/lib/pq"
 )
 
 // NewDB returns a new DB.
@@ -18,7 +20,14 @@ func NewDB() *DB {
 type DB struct {
 	conn *Connection
 	tx   *sql.Tx
+	ctx  context.Context
 	err  error
+
+	txOptions *TxOptions
+
+	useSavepoints bool
+	savepoint     string
+	savepointDone bool
 }
 
 // WithConn sets the connection for the context.
@@ -32,24 +41,68 @@ func (db *DB) Conn() *Connection {
 	return db.conn
 }
 
+// WithContext sets the context for the db context.
+// The context is used when beginning transactions and is passed on to invocations.
+func (db *DB) WithContext(ctx context.Context) *DB {
+	db.ctx = ctx
+	return db
+}
+
+// Context returns the context for the db context, defaulting to `context.Background()`.
+func (db *DB) Context() context.Context {
+	if db.ctx != nil {
+		return db.ctx
+	}
+	return context.Background()
+}
+
+// WithTxOptions sets the options used when the context begins a transaction.
+func (db *DB) WithTxOptions(opts *TxOptions) *DB {
+	db.txOptions = opts
+	return db
+}
+
+// TxOptions returns the options used when the context begins a transaction.
+func (db *DB) TxOptions() *TxOptions {
+	return db.txOptions
+}
+
+// InTxWithOptions isolates a context to a transaction started with the given options.
+// The options are remembered by the context, see `InTx(...)` for how transactions are resolved.
+func (db *DB) InTxWithOptions(opts *TxOptions) *DB {
+	return db.WithTxOptions(opts).InTx()
+}
+
+// WithSavepoints instructs `InTx()` to create a savepoint when the context already has a transaction.
+// This lets nested units of work commit or roll back independently of the outer transaction.
+func (db *DB) WithSavepoints() *DB {
+	db.useSavepoints = true
+	return db
+}
+
 // InTx isolates a context to a transaction.
 // The order precedence of the three main transaction sources are as follows:
 // - InTx(...) transaction arguments will be used above everything else
 // - an existing transaction on the context (i.e. if you call `.InTx().InTx()`)
-// - beginning a new transaction with the connection
+// - beginning a new transaction with the connection (using the context's `TxOptions()`)
+// If the context was created `WithSavepoints()` and already has a transaction, a new context is returned that
+// owns a savepoint within the existing transaction; `Commit()` and `Rollback()` release or roll back to that savepoint.
 func (db *DB) InTx(txs ...*sql.Tx) *DB {
 	if len(txs) > 0 {
 		db.tx = txs[0]
 		return db
 	}
 	if db.tx != nil {
+		if db.useSavepoints {
+			return db.nested()
+		}
 		return db
 	}
 	if db.conn == nil {
 		db.err = exception.Newf(connectionErrorMessage)
 		return db
 	}
-	db.tx, db.err = db.conn.Begin()
+	db.tx, db.err = db.conn.BeginTx(db.Context(), db.txOptions)
 	return db
 }
 
@@ -59,21 +112,44 @@ func (db *DB) Tx() *sql.Tx {
 }
 
 // Commit calls `Commit()` on the underlying transaction.
+// If the context owns a savepoint, the savepoint is released instead.
 func (db *DB) Commit() error {
 	if db.tx == nil {
 		return nil
 	}
+	if len(db.savepoint) > 0 {
+		return db.finishSavepoint(db.Release)
+	}
 	return db.tx.Commit()
 }
 
 // Rollback calls `Rollback()` on the underlying transaction.
+// If the context owns a savepoint, the transaction is rolled back to the savepoint instead.
 func (db *DB) Rollback() error {
 	if db.tx == nil {
 		return nil
 	}
+	if len(db.savepoint) > 0 {
+		return db.finishSavepoint(db.RollbackTo)
+	}
 	return db.tx.Rollback()
 }
 
+// Savepoint creates a savepoint with a given name within the context's transaction.
+func (db *DB) Savepoint(name string) error {
+	return db.execInTx("SAVEPOINT " + pq.QuoteIdentifier(name))
+}
+
+// RollbackTo rolls the context's transaction back to a named savepoint.
+func (db *DB) RollbackTo(name string) error {
+	return db.execInTx("ROLLBACK TO SAVEPOINT " + pq.QuoteIdentifier(name))
+}
+
+// Release releases (i.e. keeps the changes made since) a named savepoint.
+func (db *DB) Release(name string) error {
+	return db.execInTx("RELEASE SAVEPOINT " + pq.QuoteIdentifier(name))
+}
+
 // Err returns the carried error.
 func (db *DB) Err() error {
 	return db.err
@@ -81,5 +157,41 @@ func (db *DB) Err() error {
 
 // Invoke starts a new invocation.
 func (db *DB) Invoke() *Invocation {
-	return &Invocation{db: db, err: db.err}
+	return &Invocation{db: db, ctx: db.ctx, err: db.err}
+}
+
+// --------------------------------------------------------------------------------
+// helpers
+// --------------------------------------------------------------------------------
+
+// nested returns a new context that owns a savepoint within the current transaction.
+func (db *DB) nested() *DB {
+	child := &DB{
+		conn:          db.conn,
+		tx:            db.tx,
+		ctx:           db.ctx,
+		txOptions:     db.txOptions,
+		useSavepoints: true,
+		savepoint:     "spiffy_" + UUIDv4().ToShortString(),
+	}
+	child.err = child.Savepoint(child.savepoint)
+	return child
+}
+
+// finishSavepoint releases or rolls back the savepoint owned by the context exactly once.
+func (db *DB) finishSavepoint(action func(string) error) error {
+	if db.savepointDone {
+		return sql.ErrTxDone
+	}
+	db.savepointDone = true
+	return action(db.savepoint)
+}
+
+// execInTx runs a statement without arguments directly on the context's transaction.
+func (db *DB) execInTx(statement string) error {
+	if db.tx == nil {
+		return exception.New("savepoints require a transaction; use `InTx()` first")
+	}
+	_, err := db.tx.ExecContext(db.Context(), statement)
+	return exception.Wrap(err)
 }
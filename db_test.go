@@ -1,8 +1,11 @@
 package spiffy
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	assert "github.com/blendlabs/go-assert"
 )
@@ -66,3 +69,92 @@ func TestCtxInvokeCarriesError(t *testing.T) {
 	inv := ctx.Invoke()
 	assert.NotNil(inv.check())
 }
+
+func TestCtxWithContext(t *testing.T) {
+	assert := assert.New(t)
+
+	db := NewDB()
+	assert.NotNil(db.Context())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db = db.WithContext(ctx)
+	assert.Equal(ctx, db.Context())
+	assert.Equal(ctx, db.Invoke().Context())
+}
+
+func TestCtxInTxWithOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := &TxOptions{Isolation: sql.LevelRepeatableRead}
+	withTx := NewDB().WithConn(Default()).InTxWithOptions(opts)
+	defer withTx.Rollback()
+	assert.Nil(withTx.Err())
+	assert.NotNil(withTx.Tx())
+	assert.Equal(opts, withTx.TxOptions())
+
+	var isolation string
+	err := withTx.Invoke().Query("SHOW transaction_isolation").Scan(&isolation)
+	assert.Nil(err)
+	assert.Equal("repeatable read", isolation)
+}
+
+func TestCtxSavepoints(t *testing.T) {
+	assert := assert.New(t)
+
+	outer := NewDB().WithConn(Default()).InTx()
+	defer outer.Rollback()
+	assert.Nil(outer.Err())
+
+	assert.Nil(createUpserObjectTable(outer.Tx()))
+
+	kept := &upsertObj{UUID: UUIDv4().ToShortString(), Timestamp: time.Now().UTC(), Category: "kept"}
+	assert.Nil(outer.Invoke().Create(kept))
+
+	assert.Nil(outer.Savepoint("before_discarded"))
+	discarded := &upsertObj{UUID: UUIDv4().ToShortString(), Timestamp: time.Now().UTC(), Category: "discarded"}
+	assert.Nil(outer.Invoke().Create(discarded))
+	assert.Nil(outer.RollbackTo("before_discarded"))
+	assert.Nil(outer.Release("before_discarded"))
+
+	exists, err := outer.Invoke().Exists(kept)
+	assert.Nil(err)
+	assert.True(exists)
+
+	exists, err = outer.Invoke().Exists(discarded)
+	assert.Nil(err)
+	assert.False(exists)
+}
+
+func TestCtxInTxWithSavepoints(t *testing.T) {
+	assert := assert.New(t)
+
+	outer := NewDB().WithConn(Default()).WithSavepoints().InTx()
+	defer outer.Rollback()
+	assert.Nil(outer.Err())
+	assert.Nil(createUpserObjectTable(outer.Tx()))
+
+	inner := outer.InTx()
+	assert.Nil(inner.Err())
+	assert.False(inner == outer, "nested contexts should own their own savepoint")
+	assert.Equal(outer.Tx(), inner.Tx())
+
+	obj := &upsertObj{UUID: UUIDv4().ToShortString(), Timestamp: time.Now().UTC(), Category: "inner"}
+	assert.Nil(inner.Invoke().Create(obj))
+
+	// a failing statement would normally abort the whole transaction.
+	assert.NotNil(inner.Invoke().Exec("select * from not_a_table"))
+	assert.Nil(inner.Rollback())
+	assert.Equal(sql.ErrTxDone, inner.Rollback())
+
+	exists, err := outer.Invoke().Exists(obj)
+	assert.Nil(err)
+	assert.False(exists)
+}
+
+func TestCtxSavepointWithoutTransaction(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NotNil(NewDB().WithConn(Default()).Savepoint("test"))
+}
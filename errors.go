@@ -0,0 +1,206 @@
+package spiffy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// --------------------------------------------------------------------------------
+// Rows Affected Errors
+// --------------------------------------------------------------------------------
+
+// ErrNoRowsAffected is returned by `Update` and `Delete` when no rows matched and the invocation `RequireRowsAffected()`.
+var ErrNoRowsAffected = errors.New("spiffy: no rows affected")
+
+// IsNoRowsAffected returns if an error is `ErrNoRowsAffected`.
+func IsNoRowsAffected(err error) bool {
+	return matchesError(err, func(e error) bool {
+		return e == ErrNoRowsAffected
+	})
+}
+
+// --------------------------------------------------------------------------------
+// Version Errors
+// --------------------------------------------------------------------------------
+
+// VersionConflictError is returned by `Update` and `Upsert` when an object's version column no longer matches the row,
+// i.e. the row was changed or deleted by another writer after the object was read.
+type VersionConflictError struct {
+	// Table is the table of the object.
+	Table string
+	// Column is the version column name.
+	Column string
+	// Version is the version the object expected the row to have.
+	Version interface{}
+}
+
+// Error implements error.
+func (vce *VersionConflictError) Error() string {
+	return fmt.Sprintf("spiffy: version conflict; no row in `%s` matched %s = %v", vce.Table, vce.Column, vce.Version)
+}
+
+// IsVersionConflict returns if an error is a `*VersionConflictError`.
+func IsVersionConflict(err error) bool {
+	return matchesError(err, func(e error) bool {
+		_, isTyped := e.(*VersionConflictError)
+		return isTyped
+	})
+}
+
+// newVersionConflictError returns a new version conflict error.
+func newVersionConflictError(tableName string, version *Column, expected interface{}) error {
+	return &VersionConflictError{Table: tableName, Column: version.ColumnName, Version: expected}
+}
+
+// --------------------------------------------------------------------------------
+// Validation Errors
+// --------------------------------------------------------------------------------
+
+// FieldError is a single validation failure.
+type FieldError struct {
+	// Field is the struct field name, empty for failures from a `Validator` that aren't about one field.
+	Field string
+	// Column is the column name of the field, if it is mapped.
+	Column string
+	// Message describes the failure.
+	Message string
+}
+
+// ValidationError is returned by writes when an object fails validation, before any sql is run.
+// It lists every failing field rather than only the first.
+type ValidationError struct {
+	// Table is the table of the object.
+	Table string
+	// Fields are the validation failures.
+	Fields []FieldError
+}
+
+// Error implements error.
+func (ve *ValidationError) Error() string {
+	buffer := bytes.NewBufferString(fmt.Sprintf("spiffy: validation failed for `%s`: ", ve.Table))
+	for x, field := range ve.Fields {
+		if x > 0 {
+			buffer.WriteString("; ")
+		}
+		if len(field.Column) > 0 {
+			buffer.WriteString(field.Column + " ")
+		} else if len(field.Field) > 0 {
+			buffer.WriteString(field.Field + " ")
+		}
+		buffer.WriteString(field.Message)
+	}
+	return buffer.String()
+}
+
+// IsValidationError returns if an error is a `*ValidationError`.
+func IsValidationError(err error) bool {
+	return matchesError(err, func(e error) bool {
+		_, isTyped := e.(*ValidationError)
+		return isTyped
+	})
+}
+
+// --------------------------------------------------------------------------------
+// JSON Errors
+// --------------------------------------------------------------------------------
+
+// JSONError is returned when the value of a `json` column can't be marshalled for a write or unmarshalled from a read.
+type JSONError struct {
+	// Table is the table of the object.
+	Table string
+	// Column is the json column name.
+	Column string
+	// Err is the marshalling error.
+	Err error
+}
+
+// Error implements error.
+func (je *JSONError) Error() string {
+	return fmt.Sprintf("spiffy: json column `%s` of `%s`: %v", je.Column, je.Table, je.Err)
+}
+
+// IsJSONError returns if an error is a `*JSONError`.
+func IsJSONError(err error) bool {
+	return matchesError(err, func(e error) bool {
+		_, isTyped := e.(*JSONError)
+		return isTyped
+	})
+}
+
+// newJSONError returns a new json error for a column.
+func newJSONError(col Column, err error) error {
+	return &JSONError{Table: col.TableName, Column: col.ColumnName, Err: err}
+}
+
+// --------------------------------------------------------------------------------
+// Context Errors
+// --------------------------------------------------------------------------------
+
+// ContextCanceledError is returned when an operation is abandoned because its context was canceled
+// or its deadline was exceeded.
+type ContextCanceledError struct {
+	// Statement is the sql statement that was running when the context finished.
+	Statement string
+	// Cause is the context error, either `context.Canceled` or `context.DeadlineExceeded`.
+	Cause error
+}
+
+// Error implements error.
+func (cce *ContextCanceledError) Error() string {
+	if len(cce.Statement) > 0 {
+		return fmt.Sprintf("spiffy: statement abandoned: %v\n%s", cce.Cause, cce.Statement)
+	}
+	return fmt.Sprintf("spiffy: statement abandoned: %v", cce.Cause)
+}
+
+// IsDeadlineExceeded returns if the error was caused by the context deadline passing.
+func (cce *ContextCanceledError) IsDeadlineExceeded() bool {
+	return cce.Cause == context.DeadlineExceeded
+}
+
+// IsContextCanceled returns if an error was caused by a context being canceled or timing out.
+func IsContextCanceled(err error) bool {
+	return matchesError(err, func(e error) bool {
+		if _, isTyped := e.(*ContextCanceledError); isTyped {
+			return true
+		}
+		return e == context.Canceled || e == context.DeadlineExceeded
+	})
+}
+
+// contextCanceledError returns a `*ContextCanceledError` if the context is done, otherwise it returns the original error.
+func contextCanceledError(ctx context.Context, err error, statement string) error {
+	if err == nil || ctx == nil {
+		return err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return &ContextCanceledError{Statement: statement, Cause: ctxErr}
+	}
+	return err
+}
+
+// --------------------------------------------------------------------------------
+// helpers
+// --------------------------------------------------------------------------------
+
+// innerError is implemented by exceptions that nest an inner error.
+type innerError interface {
+	Inner() error
+}
+
+// matchesError walks an error and any nested inner errors, returning true if the predicate matches any of them.
+func matchesError(err error, predicate func(error) bool) bool {
+	for err != nil {
+		if predicate(err) {
+			return true
+		}
+		typed, hasInner := err.(innerError)
+		if !hasInner {
+			return false
+		}
+		err = typed.Inner()
+	}
+	return false
+}
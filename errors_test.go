@@ -0,0 +1,51 @@
+package spiffy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+func TestIsContextCanceled(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsContextCanceled(context.Canceled))
+	assert.True(IsContextCanceled(context.DeadlineExceeded))
+	assert.True(IsContextCanceled(&ContextCanceledError{Cause: context.Canceled}))
+	assert.False(IsContextCanceled(fmt.Errorf("this is only a test")))
+	assert.False(IsContextCanceled(nil))
+}
+
+func TestContextCanceledError(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := contextCanceledError(ctx, fmt.Errorf("test"), "select 1")
+	assert.False(IsContextCanceled(err))
+
+	cancel()
+	err = contextCanceledError(ctx, fmt.Errorf("test"), "select 1")
+	assert.True(IsContextCanceled(err))
+	assert.False(err.(*ContextCanceledError).IsDeadlineExceeded())
+	assert.Nil(contextCanceledError(ctx, nil, "select 1"))
+}
+
+func TestIsNoRowsAffected(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsNoRowsAffected(ErrNoRowsAffected))
+	assert.False(IsNoRowsAffected(fmt.Errorf("this is only a test")))
+	assert.False(IsNoRowsAffected(fmt.Errorf("spiffy: no rows affected")), "only the sentinel itself matches, not its message")
+	assert.False(IsNoRowsAffected(nil))
+}
+
+func TestIsVersionConflict(t *testing.T) {
+	assert := assert.New(t)
+
+	err := newVersionConflictError("versioned_object", &Column{ColumnName: "version"}, 1)
+	assert.True(IsVersionConflict(err))
+	assert.NotEmpty(err.Error())
+	assert.False(IsVersionConflict(ErrNoRowsAffected))
+}
@@ -12,6 +12,9 @@ const (
 
 	// EventFlagQuery is a logger.EventFlag
 	EventFlagQuery logger.EventFlag = "db.query"
+
+	// EventFlagRetry is a logger.EventFlag fired when a transaction is retried.
+	EventFlagRetry logger.EventFlag = "db.retry"
 )
 
 // EventListener is an event listener for logger events.
@@ -0,0 +1,67 @@
+package spiffy
+
+import "reflect"
+
+// hookEvent is a point in an object's lifecycle that it can hook into.
+type hookEvent int
+
+const (
+	hookBeforeCreate hookEvent = iota
+	hookAfterCreate
+	hookBeforeUpdate
+	hookAfterUpdate
+	hookBeforeDelete
+	hookAfterDelete
+	hookAfterGet
+)
+
+// runHook calls the hook for an event if the object implements it.
+// Errors from hooks are returned as they are, so that callers can inspect them.
+func runHook(db *DB, object interface{}, event hookEvent) error {
+	switch event {
+	case hookBeforeCreate:
+		if hook, isHook := object.(BeforeCreateHook); isHook {
+			return hook.BeforeCreate(db)
+		}
+	case hookAfterCreate:
+		if hook, isHook := object.(AfterCreateHook); isHook {
+			return hook.AfterCreate(db)
+		}
+	case hookBeforeUpdate:
+		if hook, isHook := object.(BeforeUpdateHook); isHook {
+			return hook.BeforeUpdate(db)
+		}
+	case hookAfterUpdate:
+		if hook, isHook := object.(AfterUpdateHook); isHook {
+			return hook.AfterUpdate(db)
+		}
+	case hookBeforeDelete:
+		if hook, isHook := object.(BeforeDeleteHook); isHook {
+			return hook.BeforeDelete(db)
+		}
+	case hookAfterDelete:
+		if hook, isHook := object.(AfterDeleteHook); isHook {
+			return hook.AfterDelete(db)
+		}
+	case hookAfterGet:
+		if hook, isHook := object.(AfterGetHook); isHook {
+			return hook.AfterGet(db)
+		}
+	}
+	return nil
+}
+
+// runHooks calls the hook for an event on each element of a slice, by reference where the element can be addressed.
+func runHooks(db *DB, sliceValue reflect.Value, event hookEvent) error {
+	for x := 0; x < sliceValue.Len(); x++ {
+		element := sliceValue.Index(x)
+		object, isSettable := settableElement(element)
+		if !isSettable {
+			object = element.Interface()
+		}
+		if err := runHook(db, object, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
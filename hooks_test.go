@@ -0,0 +1,154 @@
+package spiffy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+type hookedObj struct {
+	ID        int       `db:"id,pk,serial"`
+	Name      string    `db:"name"`
+	Timestamp time.Time `db:"timestamp_utc"`
+	Amount    float32   `db:"amount"`
+	Pending   bool      `db:"pending"`
+	Category  string    `db:"category"`
+
+	calls  []string `db:"-"`
+	failOn string   `db:"-"`
+}
+
+func (ho hookedObj) TableName() string {
+	return "bench_object"
+}
+
+func (ho *hookedObj) record(db *DB, call string) error {
+	if db == nil {
+		return fmt.Errorf("%s: nil db", call)
+	}
+	ho.calls = append(ho.calls, call)
+	if ho.failOn == call {
+		return fmt.Errorf("%s failed", call)
+	}
+	return nil
+}
+
+func (ho *hookedObj) BeforeCreate(db *DB) error {
+	ho.Category = "derived_" + ho.Name
+	return ho.record(db, "before_create")
+}
+func (ho *hookedObj) AfterCreate(db *DB) error  { return ho.record(db, "after_create") }
+func (ho *hookedObj) BeforeUpdate(db *DB) error { return ho.record(db, "before_update") }
+func (ho *hookedObj) AfterUpdate(db *DB) error  { return ho.record(db, "after_update") }
+func (ho *hookedObj) BeforeDelete(db *DB) error { return ho.record(db, "before_delete") }
+func (ho *hookedObj) AfterDelete(db *DB) error  { return ho.record(db, "after_delete") }
+func (ho *hookedObj) AfterGet(db *DB) error     { return ho.record(db, "after_get") }
+
+func TestRunHook(t *testing.T) {
+	assert := assert.New(t)
+
+	obj := &hookedObj{}
+	db := &DB{}
+	assert.Nil(runHook(db, obj, hookBeforeCreate))
+	assert.Nil(runHook(db, obj, hookAfterGet))
+	assert.Equal([]string{"before_create", "after_get"}, obj.calls)
+
+	assert.Nil(runHook(db, hookedObj{}, hookAfterGet), "value receivers don't implement the pointer hooks")
+	assert.Nil(runHook(db, &benchObj{}, hookBeforeCreate))
+
+	obj.failOn = "before_delete"
+	assert.NotNil(runHook(db, obj, hookBeforeDelete))
+
+	objs := []hookedObj{{}, {}}
+	assert.Nil(runHooks(db, reflectValue(objs), hookAfterCreate))
+	assert.Equal([]string{"after_create"}, objs[1].calls)
+}
+
+func TestInvocationHooks(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createTable(tx))
+
+	obj := &hookedObj{Name: "foo", Timestamp: time.Now().UTC()}
+	assert.Nil(Default().CreateInTx(obj, tx))
+	assert.Equal([]string{"before_create", "after_create"}, obj.calls)
+
+	var verify hookedObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, obj.ID))
+	assert.Equal("derived_foo", verify.Category)
+	assert.Equal([]string{"after_get"}, verify.calls)
+
+	obj.calls = nil
+	assert.Nil(Default().UpdateInTx(obj, tx))
+	assert.Equal([]string{"before_update", "after_update"}, obj.calls)
+
+	obj.calls = nil
+	obj.failOn = "before_delete"
+	assert.NotNil(Default().DeleteInTx(obj, tx))
+	exists, err := Default().ExistsInTx(obj, tx)
+	assert.Nil(err)
+	assert.True(exists, "a failing before hook should abort the delete")
+
+	obj.calls = nil
+	obj.failOn = ""
+	assert.Nil(Default().DeleteInTx(obj, tx))
+	assert.Equal([]string{"before_delete", "after_delete"}, obj.calls)
+
+	created := &hookedObj{Name: "created", Timestamp: time.Now().UTC()}
+	assert.Nil(Default().CreateIfNotExistsInTx(created, tx))
+	assert.Equal([]string{"before_create", "after_create"}, created.calls)
+
+	many := []hookedObj{{Name: "bar", Timestamp: time.Now().UTC()}, {Name: "baz", Timestamp: time.Now().UTC()}}
+	assert.Nil(Default().CreateManyInTx(many, tx))
+	assert.Equal([]string{"before_create", "after_create"}, many[0].calls)
+	assert.Equal("derived_baz", many[1].Category)
+}
+
+type queryingHookObj struct {
+	ID        int       `db:"id,pk,serial"`
+	Name      string    `db:"name"`
+	Timestamp time.Time `db:"timestamp_utc"`
+	Amount    float32   `db:"amount"`
+	Pending   bool      `db:"pending"`
+	Category  string    `db:"category"`
+
+	count int `db:"-"`
+}
+
+func (qo queryingHookObj) TableName() string {
+	return "bench_object"
+}
+
+func (qo *queryingHookObj) AfterGet(db *DB) error {
+	return db.Invoke().Query("select count(*) from bench_object").Scan(&qo.count)
+}
+
+func TestInvocationAfterGetQueriesInTx(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createTable(tx))
+	assert.Nil(createObject(0, tx))
+	assert.Nil(createObject(1, tx))
+
+	var expected int
+	assert.Nil(Default().QueryInTx("select count(*) from bench_object", tx).Scan(&expected))
+
+	var all []queryingHookObj
+	assert.Nil(Default().GetAllInTx(&all, tx))
+	assert.Len(all, expected)
+	for _, obj := range all {
+		assert.Equal(expected, obj.count)
+	}
+
+	var verify queryingHookObj
+	assert.Nil(Default().GetByIDInTx(&verify, tx, all[0].ID))
+	assert.Equal(expected, verify.count)
+}
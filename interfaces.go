@@ -21,5 +21,55 @@ type Populatable interface {
 	Populate(rows *sql.Rows) error
 }
 
+// Validator is an interface you can implement to add your own validation to the column tag rules that run before writes.
+// A `*ValidationError` returned from `Validate()` has its fields merged with the tag rule failures; any other error is
+// reported as a failure of the object as a whole.
+type Validator interface {
+	Validate() error
+}
+
 // RowsConsumer is the function signature that is called from within Each().
 type RowsConsumer func(r *sql.Rows) error
+
+// ObjectConsumer is the function signature that is called from within EachObject(), with a pointer to a new object for each row.
+type ObjectConsumer func(object interface{}) error
+
+// JoinedConsumer is the function signature that is called from within EachJoined(), with a pointer to a new object
+// for each join target of a row, or nil for the targets the row has no values for.
+type JoinedConsumer func(objects []interface{}) error
+
+// BeforeCreateHook is an interface you can implement to run logic before an object is inserted by Create() or CreateMany().
+// The hook is passed the invocation's db context so it can run in the same transaction; returning an error aborts the insert.
+type BeforeCreateHook interface {
+	BeforeCreate(db *DB) error
+}
+
+// AfterCreateHook is an interface you can implement to run logic after an object is inserted by Create() or CreateMany().
+type AfterCreateHook interface {
+	AfterCreate(db *DB) error
+}
+
+// BeforeUpdateHook is an interface you can implement to run logic before an object is updated by Update(), UpdateColumns() or UpdateChanged().
+type BeforeUpdateHook interface {
+	BeforeUpdate(db *DB) error
+}
+
+// AfterUpdateHook is an interface you can implement to run logic after an object is updated by Update(), UpdateColumns() or UpdateChanged().
+type AfterUpdateHook interface {
+	AfterUpdate(db *DB) error
+}
+
+// BeforeDeleteHook is an interface you can implement to run logic before an object is deleted by Delete().
+type BeforeDeleteHook interface {
+	BeforeDelete(db *DB) error
+}
+
+// AfterDeleteHook is an interface you can implement to run logic after an object is deleted by Delete().
+type AfterDeleteHook interface {
+	AfterDelete(db *DB) error
+}
+
+// AfterGetHook is an interface you can implement to run logic after an object is read by Get(), GetAll() or GetWhere().
+type AfterGetHook interface {
+	AfterGet(db *DB) error
+}
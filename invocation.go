@@ -1,6 +1,7 @@
 package spiffy
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -9,18 +10,27 @@ import (
 
 	exception "github.com/blendlabs/go-exception"
 	logger "github.com/blendlabs/go-logger"
+	"github.com/lib/pq"
 )
 
 const (
 	connectionErrorMessage = "invocation context; db connection is nil"
+
+	// maxStatementParameters is the most bind parameters postgres allows in a single statement.
+	maxStatementParameters = 65535
 )
 
 // Invocation is a specific operation against a context.
 type Invocation struct {
 	db             *DB
+	ctx            context.Context
 	fireEvents     bool
 	statementLabel string
 	err            error
+
+	requireRowsAffected bool
+	unscoped            bool
+	hardDelete          bool
 }
 
 // Err returns the context's error.
@@ -34,11 +44,45 @@ func (i *Invocation) WithLabel(label string) *Invocation {
 	return i
 }
 
+// RequireRowsAffected instructs `Update` and `Delete` to return `ErrNoRowsAffected` if no rows matched the object's primary key.
+func (i *Invocation) RequireRowsAffected() *Invocation {
+	i.requireRowsAffected = true
+	return i
+}
+
+// Unscoped instructs `Get`, `GetAll`, `GetWhere` and `Exists` to include rows that have been soft deleted.
+func (i *Invocation) Unscoped() *Invocation {
+	i.unscoped = true
+	return i
+}
+
+// HardDelete instructs `Delete` and `DeleteWhere` to remove rows even if their type has a soft delete column.
+func (i *Invocation) HardDelete() *Invocation {
+	i.hardDelete = true
+	return i
+}
+
 // Label returns the statement / plan cache label for the context.
 func (i *Invocation) Label() string {
 	return i.statementLabel
 }
 
+// WithContext sets the context for the invocation.
+// The context is passed to the underlying prepare, query and exec calls, and if it is canceled
+// or times out the invocation will return a `*ContextCanceledError`.
+func (i *Invocation) WithContext(ctx context.Context) *Invocation {
+	i.ctx = ctx
+	return i
+}
+
+// Context returns the context for the invocation, defaulting to `context.Background()`.
+func (i *Invocation) Context() context.Context {
+	if i.ctx != nil {
+		return i.ctx
+	}
+	return context.Background()
+}
+
 // Tx returns the underlying transaction.
 func (i *Invocation) Tx() *sql.Tx {
 	return i.db.tx
@@ -50,13 +94,19 @@ func (i *Invocation) Prepare(statement string) (*sql.Stmt, error) {
 		return nil, i.err
 	}
 	if len(i.statementLabel) > 0 {
-		return i.db.conn.PrepareCached(i.statementLabel, statement, i.db.tx)
+		return i.db.conn.PrepareCachedContext(i.Context(), i.statementLabel, statement, i.db.tx)
 	}
-	return i.db.conn.Prepare(statement, i.db.tx)
+	return i.db.conn.PrepareContext(i.Context(), statement, i.db.tx)
 }
 
 // Exec executes a sql statement with a given set of arguments.
 func (i *Invocation) Exec(statement string, args ...interface{}) (err error) {
+	_, err = i.ExecWithResult(statement, args...)
+	return
+}
+
+// ExecWithResult executes a sql statement with a given set of arguments and returns the `sql.Result`.
+func (i *Invocation) ExecWithResult(statement string, args ...interface{}) (res sql.Result, err error) {
 	err = i.check()
 	if err != nil {
 		return
@@ -73,7 +123,8 @@ func (i *Invocation) Exec(statement string, args ...interface{}) (err error) {
 
 	defer i.closeStatement(err, stmt)
 
-	if _, execErr := stmt.Exec(args...); execErr != nil {
+	var execErr error
+	if res, execErr = stmt.ExecContext(i.Context(), args...); execErr != nil {
 		err = exception.Wrap(execErr)
 		if err != nil {
 			i.invalidateCachedStatement()
@@ -86,7 +137,7 @@ func (i *Invocation) Exec(statement string, args ...interface{}) (err error) {
 
 // Query returns a new query object for a given sql query and arguments.
 func (i *Invocation) Query(query string, args ...interface{}) *Query {
-	return &Query{statement: query, args: args, start: time.Now(), db: i.db, err: i.check(), statementLabel: i.statementLabel}
+	return &Query{statement: query, args: args, start: time.Now(), db: i.db, ctx: i.ctx, err: i.check(), statementLabel: i.statementLabel}
 }
 
 // Get returns a given object based on a group of primary key ids within a transaction.
@@ -105,11 +156,15 @@ func (i *Invocation) Get(object DatabaseMapped, ids ...interface{}) (err error)
 	}
 
 	meta := getCachedColumnCollectionFromInstance(object)
+	if err = meta.Err(); err != nil {
+		return
+	}
 	standardCols := meta.NotReadOnly()
 	tableName := object.TableName()
 
+	softDelete := i.scopeColumn(meta)
 	if len(i.statementLabel) == 0 {
-		i.statementLabel = fmt.Sprintf("%s_get", tableName)
+		i.statementLabel = fmt.Sprintf("%s_get%s", tableName, i.scopeLabel(meta))
 	}
 
 	columnNames := standardCols.ColumnNames()
@@ -144,6 +199,10 @@ func (i *Invocation) Get(object DatabaseMapped, ids ...interface{}) (err error)
 		}
 	}
 
+	if softDelete != nil {
+		queryBodyBuffer.WriteString(" AND " + softDelete.ColumnName + " IS NULL")
+	}
+
 	queryBody = queryBodyBuffer.String()
 	stmt, stmtErr := i.Prepare(queryBody)
 	if stmtErr != nil {
@@ -152,7 +211,7 @@ func (i *Invocation) Get(object DatabaseMapped, ids ...interface{}) (err error)
 	}
 	defer i.closeStatement(err, stmt)
 
-	rows, queryErr := stmt.Query(ids...)
+	rows, queryErr := stmt.QueryContext(i.Context(), ids...)
 	if queryErr != nil {
 		err = exception.Wrap(queryErr)
 		i.invalidateCachedStatement()
@@ -166,25 +225,97 @@ func (i *Invocation) Get(object DatabaseMapped, ids ...interface{}) (err error)
 	}()
 
 	var popErr error
+	var found bool
 	if rows.Next() {
 		if isPopulatable(object) {
-			popErr = asPopulatable(object).Populate(rows)
+			popErr = exception.Wrap(asPopulatable(object).Populate(rows))
 		} else {
 			popErr = PopulateInOrder(object, rows, standardCols)
 		}
 
 		if popErr != nil {
-			err = exception.Wrap(popErr)
+			err = popErr
 			return
 		}
+		resetSnapshot(object, standardCols)
+		found = true
 	}
 
-	err = exception.Wrap(rows.Err())
+	if err = exception.Wrap(rows.Err()); err != nil {
+		return
+	}
+	// the hook can query through the same connection or transaction, which can't while the rows are open.
+	if err = exception.Wrap(rows.Close()); err != nil {
+		return
+	}
+	if found {
+		err = runHook(i.db, object, hookAfterGet)
+	}
 	return
 }
 
 // GetAll returns all rows of an object mapped table wrapped in a transaction.
 func (i *Invocation) GetAll(collection interface{}) (err error) {
+	return i.getMany(collection, "")
+}
+
+// GetWhere returns the rows of an object mapped table that match a where clause (without the `WHERE` keyword).
+// Parameters in the where clause are numbered from `$1`.
+//
+//	err := spiffy.Default().DB().Invoke().GetWhere(&objs, "category = $1 and pending = $2", "foo", true)
+func (i *Invocation) GetWhere(collection interface{}, where string, args ...interface{}) (err error) {
+	return i.getMany(collection, where, args...)
+}
+
+// Preload loads the named relation fields of a collection of objects, issuing one query per relation.
+// Relations are declared on fields with a `rel` tag, e.g. `rel:"has_many,fk=user_id"`, and the collection is a slice (or a reference to a slice) of objects or object references.
+//
+//	err := spiffy.Default().DB().Invoke().Preload(users, "Orders")
+func (i *Invocation) Preload(collection interface{}, relations ...string) (err error) {
+	err = i.check()
+	if err != nil {
+		return
+	}
+
+	collectionValue := reflectValue(collection)
+	if collectionValue.Kind() != reflect.Slice {
+		err = exception.New("preload requires a slice of objects.")
+		return
+	}
+
+	elements, err := preloadElements(collectionValue)
+	if err != nil || len(elements) == 0 {
+		return
+	}
+
+	t := reflectSliceType(collection)
+	tableName, _ := TableName(t)
+	meta := getCachedColumnCollectionFromType(newColumnCacheKey(t), t)
+	if err = meta.Err(); err != nil {
+		return
+	}
+
+	for _, name := range relations {
+		rel, relErr := relationForField(t, name)
+		if relErr != nil {
+			err = relErr
+			return
+		}
+
+		if rel.Kind == RelationHasMany {
+			err = i.preloadHasMany(elements, tableName, meta, rel)
+		} else {
+			err = i.preloadBelongsTo(elements, tableName, meta, rel)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// getMany returns the rows of an object mapped table, optionally filtered by a where clause.
+func (i *Invocation) getMany(collection interface{}, where string, args ...interface{}) (err error) {
 	err = i.check()
 	if err != nil {
 		return
@@ -198,11 +329,16 @@ func (i *Invocation) GetAll(collection interface{}) (err error) {
 	t := reflectSliceType(collection)
 	tableName, _ := TableName(t)
 
-	if len(i.statementLabel) == 0 {
-		i.statementLabel = fmt.Sprintf("%s_get_all", tableName)
+	cols := getCachedColumnCollectionFromType(newColumnCacheKey(t), t)
+	if err = cols.Err(); err != nil {
+		return
 	}
+	meta := cols.NotReadOnly()
+	softDelete := i.scopeColumn(meta)
 
-	meta := getCachedColumnCollectionFromType(tableName, t).NotReadOnly()
+	if len(i.statementLabel) == 0 && len(where) == 0 {
+		i.statementLabel = fmt.Sprintf("%s_get_all%s", tableName, i.scopeLabel(meta))
+	}
 
 	columnNames := meta.ColumnNames()
 
@@ -219,6 +355,15 @@ func (i *Invocation) GetAll(collection interface{}) (err error) {
 	queryBodyBuffer.WriteString(" FROM ")
 	queryBodyBuffer.WriteString(tableName)
 
+	if len(where) > 0 && softDelete != nil {
+		queryBodyBuffer.WriteString(" WHERE (" + where + ") AND " + softDelete.ColumnName + " IS NULL")
+	} else if len(where) > 0 {
+		queryBodyBuffer.WriteString(" WHERE ")
+		queryBodyBuffer.WriteString(where)
+	} else if softDelete != nil {
+		queryBodyBuffer.WriteString(" WHERE " + softDelete.ColumnName + " IS NULL")
+	}
+
 	queryBody = queryBodyBuffer.String()
 	stmt, stmtErr := i.Prepare(queryBody)
 	if stmtErr != nil {
@@ -228,7 +373,7 @@ func (i *Invocation) GetAll(collection interface{}) (err error) {
 	}
 	defer func() { err = i.closeStatement(err, stmt) }()
 
-	rows, queryErr := stmt.Query()
+	rows, queryErr := stmt.QueryContext(i.Context(), args...)
 	if queryErr != nil {
 		err = exception.Wrap(queryErr)
 		return
@@ -246,6 +391,7 @@ func (i *Invocation) GetAll(collection interface{}) (err error) {
 	}
 	isPopulatable := isPopulatable(v)
 
+	existing := collectionValue.Len()
 	var popErr error
 	for rows.Next() {
 		newObj, _ := makeNewDatabaseMapped(t)
@@ -255,15 +401,23 @@ func (i *Invocation) GetAll(collection interface{}) (err error) {
 		} else {
 			popErr = PopulateInOrder(newObj, rows, meta)
 			if popErr != nil {
-				err = exception.Wrap(popErr)
+				err = popErr
 				return
 			}
 		}
+		takeSnapshot(newObj, meta)
 		newObjValue := reflectValue(newObj)
 		collectionValue.Set(reflect.Append(collectionValue, newObjValue))
 	}
 
-	err = exception.Wrap(rows.Err())
+	if err = exception.Wrap(rows.Err()); err != nil {
+		return
+	}
+	// the hooks can query through the same connection or transaction, which can't while the rows are open.
+	if err = exception.Wrap(rows.Close()); err != nil {
+		return
+	}
+	err = runHooks(i.db, collectionValue.Slice(existing, collectionValue.Len()), hookAfterGet)
 	return
 }
 
@@ -278,8 +432,18 @@ func (i *Invocation) Create(object DatabaseMapped) (err error) {
 	start := time.Now()
 	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
 
+	if err = runHook(i.db, object, hookBeforeCreate); err != nil {
+		return
+	}
+
 	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
 	writeCols := cols.NotReadOnly().NotSerials()
+	if err = validate(object, writeCols); err != nil {
+		return
+	}
 
 	//NOTE: we're only using one.
 	serials := cols.Serials()
@@ -290,7 +454,15 @@ func (i *Invocation) Create(object DatabaseMapped) (err error) {
 	}
 
 	colNames := writeCols.ColumnNames()
-	colValues := writeCols.ColumnValues(object)
+	colValues, valuesErr := writeCols.columnValues(object)
+	if valuesErr != nil {
+		err = valuesErr
+		return
+	}
+	if err = stampValues(reflect.ValueOf(object), writeCols, colValues, i.db.conn.Now(), true); err != nil {
+		err = exception.Wrap(err)
+		return
+	}
 
 	queryBodyBuffer := i.db.conn.bufferPool.Get()
 	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
@@ -328,7 +500,7 @@ func (i *Invocation) Create(object DatabaseMapped) (err error) {
 	defer func() { err = i.closeStatement(err, stmt) }()
 
 	if serials.Len() == 0 {
-		_, execErr := stmt.Exec(colValues...)
+		_, execErr := stmt.ExecContext(i.Context(), colValues...)
 		if execErr != nil {
 			err = exception.Wrap(execErr)
 			i.invalidateCachedStatement()
@@ -338,7 +510,7 @@ func (i *Invocation) Create(object DatabaseMapped) (err error) {
 		serial := serials.FirstOrDefault()
 
 		var id interface{}
-		execErr := stmt.QueryRow(colValues...).Scan(&id)
+		execErr := stmt.QueryRowContext(i.Context(), colValues...).Scan(&id)
 		if execErr != nil {
 			err = exception.Wrap(execErr)
 			return
@@ -350,10 +522,12 @@ func (i *Invocation) Create(object DatabaseMapped) (err error) {
 		}
 	}
 
-	return nil
+	resetSnapshot(object, cols.NotReadOnly())
+	return runHook(i.db, object, hookAfterCreate)
 }
 
 // CreateIfNotExists writes an object to the database if it does not already exist within a transaction.
+// The after create hook only runs if the object was written.
 func (i *Invocation) CreateIfNotExists(object DatabaseMapped) (err error) {
 	err = i.check()
 	if err != nil {
@@ -364,8 +538,18 @@ func (i *Invocation) CreateIfNotExists(object DatabaseMapped) (err error) {
 	start := time.Now()
 	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
 
+	if err = runHook(i.db, object, hookBeforeCreate); err != nil {
+		return
+	}
+
 	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
 	writeCols := cols.NotReadOnly().NotSerials()
+	if err = validate(object, writeCols); err != nil {
+		return
+	}
 
 	//NOTE: we're only using one.
 	serials := cols.Serials()
@@ -377,7 +561,15 @@ func (i *Invocation) CreateIfNotExists(object DatabaseMapped) (err error) {
 	}
 
 	colNames := writeCols.ColumnNames()
-	colValues := writeCols.ColumnValues(object)
+	colValues, valuesErr := writeCols.columnValues(object)
+	if valuesErr != nil {
+		err = valuesErr
+		return
+	}
+	if err = stampValues(reflect.ValueOf(object), writeCols, colValues, i.db.conn.Now(), true); err != nil {
+		err = exception.Wrap(err)
+		return
+	}
 
 	queryBodyBuffer := i.db.conn.bufferPool.Get()
 	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
@@ -427,17 +619,30 @@ func (i *Invocation) CreateIfNotExists(object DatabaseMapped) (err error) {
 	defer func() { err = i.closeStatement(err, stmt) }()
 
 	if serials.Len() == 0 {
-		_, execErr := stmt.Exec(colValues...)
+		res, execErr := stmt.ExecContext(i.Context(), colValues...)
 		if execErr != nil {
 			err = exception.Wrap(execErr)
 			i.invalidateCachedStatement()
 			return
 		}
+		rowsAffected, rowsAffectedErr := res.RowsAffected()
+		if rowsAffectedErr != nil {
+			err = exception.Wrap(rowsAffectedErr)
+			return
+		}
+		// a conflict inserts nothing.
+		if rowsAffected == 0 {
+			return nil
+		}
 	} else {
 		serial := serials.FirstOrDefault()
 
+		// a conflict returns no row, and leaves the object as it is.
 		var id interface{}
-		execErr := stmt.QueryRow(colValues...).Scan(&id)
+		execErr := stmt.QueryRowContext(i.Context(), colValues...).Scan(&id)
+		if execErr == sql.ErrNoRows {
+			return nil
+		}
 		if execErr != nil {
 			err = exception.Wrap(execErr)
 			return
@@ -449,10 +654,15 @@ func (i *Invocation) CreateIfNotExists(object DatabaseMapped) (err error) {
 		}
 	}
 
-	return nil
+	resetSnapshot(object, cols.NotReadOnly())
+	return runHook(i.db, object, hookAfterCreate)
 }
 
 // CreateMany writes many an objects to the database within a transaction.
+// Inserts too large for one statement are split into chunks; if the invocation isn't in a transaction the chunks
+// run in one that is committed when they all succeed, so either every object is written or none are.
+// If the type has a serial column the values written for each element are set on it;
+// elements that are struct values held in interfaces (e.g. a `[]DatabaseMapped` of values) cannot be set and are skipped.
 func (i *Invocation) CreateMany(objects interface{}) (err error) {
 	err = i.check()
 	if err != nil {
@@ -474,12 +684,90 @@ func (i *Invocation) CreateMany(objects interface{}) (err error) {
 		return
 	}
 
-	cols := getCachedColumnCollectionFromType(tableName, sliceType)
+	if err = runHooks(i.db, sliceValue, hookBeforeCreate); err != nil {
+		return
+	}
+
+	cols := getCachedColumnCollectionFromType(newColumnCacheKey(sliceType), sliceType)
+	if err = cols.Err(); err != nil {
+		return
+	}
 	writeCols := cols.NotReadOnly().NotSerials()
+	if err = validateMany(sliceValue, writeCols); err != nil {
+		return
+	}
 
 	//NOTE: we're only using one.
-	//serials := cols.Serials()
+	serials := cols.Serials()
+
+	// postgres limits the number of parameters in a statement, so split the insert into chunks that fit.
+	// the serial is written with each row too.
+	paramCount := writeCols.Len()
+	if serials.Len() > 0 {
+		paramCount = paramCount + 1
+	}
+	chunkSize := sliceValue.Len()
+	if paramCount > 0 && chunkSize*paramCount > maxStatementParameters {
+		chunkSize = maxStatementParameters / paramCount
+	}
+
+	// a single statement is atomic on its own, but several chunks need a transaction so a failed chunk
+	// doesn't leave the ones before it committed.
+	chunks := i
+	if i.db.tx == nil && chunkSize < sliceValue.Len() {
+		tx, txErr := i.db.conn.BeginContext(i.Context())
+		if txErr != nil {
+			err = exception.Wrap(txErr)
+			return
+		}
+		defer func() {
+			if err != nil {
+				tx.Rollback()
+				return
+			}
+			if commitErr := tx.Commit(); commitErr != nil {
+				err = exception.Wrap(commitErr)
+			}
+		}()
+
+		inTx := *i
+		inTx.db = i.db.conn.DB().InTx(tx).WithContext(i.Context())
+		chunks = &inTx
+	}
+
+	for chunkStart := 0; chunkStart < sliceValue.Len(); chunkStart += chunkSize {
+		chunkEnd := chunkStart + chunkSize
+		if chunkEnd > sliceValue.Len() {
+			chunkEnd = sliceValue.Len()
+		}
+		queryBody, err = chunks.createManyChunk(tableName, writeCols, serials, sliceValue.Slice(chunkStart, chunkEnd))
+		if err != nil {
+			return
+		}
+	}
+	return runHooks(chunks.db, sliceValue, hookAfterCreate)
+}
+
+// createManyChunk inserts a slice of objects with a single multi-row insert statement.
+// If the type has a serial column its values are reserved from the column's sequence and written with the rows,
+// rather than read back from the insert, so each object gets the value of its own row regardless of the order
+// postgres returns rows in.
+func (i *Invocation) createManyChunk(tableName string, writeCols, serials *ColumnCollection, sliceValue reflect.Value) (queryBody string, err error) {
 	colNames := writeCols.ColumnNames()
+	rowColumns := writeCols.Len()
+
+	//NOTE: we're only using one.
+	var serial *Column
+	var ids []interface{}
+	if serials.Len() > 0 {
+		serial = serials.FirstOrDefault()
+		ids, err = i.nextSerials(tableName, serial, sliceValue.Len())
+		if err != nil {
+			return
+		}
+		colNames = append(append([]string{}, colNames...), serial.ColumnName)
+		rowColumns = rowColumns + 1
+	}
 
 	queryBodyBuffer := i.db.conn.bufferPool.Get()
 	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
@@ -499,10 +787,10 @@ func (i *Invocation) CreateMany(objects interface{}) (err error) {
 	metaIndex := 1
 	for x := 0; x < sliceValue.Len(); x++ {
 		queryBodyBuffer.WriteString("(")
-		for y := 0; y < writeCols.Len(); y++ {
+		for y := 0; y < rowColumns; y++ {
 			queryBodyBuffer.WriteString(fmt.Sprintf("$%d", metaIndex))
 			metaIndex = metaIndex + 1
-			if y < writeCols.Len()-1 {
+			if y < rowColumns-1 {
 				queryBodyBuffer.WriteRune(runeComma)
 			}
 		}
@@ -520,94 +808,112 @@ func (i *Invocation) CreateMany(objects interface{}) (err error) {
 	}
 	defer func() { err = i.closeStatement(err, stmt) }()
 
+	now := i.db.conn.Now()
 	var colValues []interface{}
 	for row := 0; row < sliceValue.Len(); row++ {
-		colValues = append(colValues, writeCols.ColumnValues(sliceValue.Index(row).Interface())...)
+		rowValues, valuesErr := writeCols.columnValues(sliceValue.Index(row).Interface())
+		if valuesErr != nil {
+			err = valuesErr
+			return
+		}
+		if err = stampValues(sliceValue.Index(row), writeCols, rowValues, now, true); err != nil {
+			err = exception.Wrap(err)
+			return
+		}
+		colValues = append(colValues, rowValues...)
+		if serial != nil {
+			colValues = append(colValues, ids[row])
+		}
 	}
 
-	_, execErr := stmt.Exec(colValues...)
+	_, execErr := stmt.ExecContext(i.Context(), colValues...)
 	if execErr != nil {
 		err = exception.Wrap(execErr)
 		i.invalidateCachedStatement()
 		return
 	}
 
-	return nil
-}
-
-// Update updates an object wrapped in a transaction.
-func (i *Invocation) Update(object DatabaseMapped) (err error) {
-	err = i.check()
-	if err != nil {
+	if serial == nil {
 		return
 	}
-
-	var queryBody string
-	start := time.Now()
-	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
-
-	tableName := object.TableName()
-	if len(i.statementLabel) == 0 {
-		i.statementLabel = fmt.Sprintf("%s_update", tableName)
-	}
-
-	cols := getCachedColumnCollectionFromInstance(object)
-	writeCols := cols.WriteColumns()
-	pks := cols.PrimaryKeys()
-	updateCols := cols.UpdateColumns()
-	updateValues := updateCols.ColumnValues(object)
-	numColumns := writeCols.Len()
-
-	queryBodyBuffer := i.db.conn.bufferPool.Get()
-	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
-
-	queryBodyBuffer.WriteString("UPDATE ")
-	queryBodyBuffer.WriteString(tableName)
-	queryBodyBuffer.WriteString(" SET ")
-
-	var writeColIndex int
-	var col Column
-	for ; writeColIndex < writeCols.Len(); writeColIndex++ {
-		col = writeCols.columns[writeColIndex]
-		queryBodyBuffer.WriteString(col.ColumnName)
-		queryBodyBuffer.WriteString(" = $" + strconv.Itoa(writeColIndex+1))
-		if writeColIndex != numColumns-1 {
-			queryBodyBuffer.WriteRune(runeComma)
-		}
-	}
-
-	queryBodyBuffer.WriteString(" WHERE ")
-	for i, pk := range pks.Columns() {
-		queryBodyBuffer.WriteString(pk.ColumnName)
-		queryBodyBuffer.WriteString(" = ")
-		queryBodyBuffer.WriteString("$" + strconv.Itoa(i+(writeColIndex+1)))
-
-		if i < (pks.Len() - 1) {
-			queryBodyBuffer.WriteString(" AND ")
+	for row := 0; row < sliceValue.Len(); row++ {
+		if target, isSettable := settableElement(sliceValue.Index(row)); isSettable {
+			if err = serial.SetValue(target, ids[row]); err != nil {
+				err = exception.Wrap(err)
+				return
+			}
 		}
 	}
+	return
+}
 
-	queryBody = queryBodyBuffer.String()
-	stmt, stmtErr := i.Prepare(queryBody)
+// nextSerials reserves a number of values from the sequence that backs a serial column.
+func (i *Invocation) nextSerials(tableName string, serial *Column, count int) (ids []interface{}, err error) {
+	stmt, stmtErr := i.db.conn.PrepareContext(i.Context(), "SELECT nextval(pg_get_serial_sequence($1, $2)) FROM generate_series(1, $3)", i.db.tx)
 	if stmtErr != nil {
 		err = exception.Wrap(stmtErr)
 		return
 	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			err = exception.Nest(err, closeErr)
+		}
+	}()
 
-	defer func() { err = i.closeStatement(err, stmt) }()
-
-	_, execErr := stmt.Exec(updateValues...)
-	if execErr != nil {
-		err = exception.Wrap(execErr)
-		i.invalidateCachedStatement()
+	rows, queryErr := stmt.QueryContext(i.Context(), tableName, serial.ColumnName, count)
+	if queryErr != nil {
+		err = exception.Wrap(queryErr)
 		return
 	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			err = exception.Nest(err, closeErr)
+		}
+	}()
 
+	for rows.Next() {
+		var id interface{}
+		if err = rows.Scan(&id); err != nil {
+			err = exception.Wrap(err)
+			return
+		}
+		if id == nil {
+			err = exception.Newf("serial column `%s` of `%s` is not backed by a sequence.", serial.ColumnName, tableName)
+			return
+		}
+		ids = append(ids, id)
+	}
+	if err = exception.Wrap(rows.Err()); err != nil {
+		return
+	}
+	if len(ids) != count {
+		err = exception.Newf("reserved %d of %d values for serial column `%s` of `%s`.", len(ids), count, serial.ColumnName, tableName)
+	}
 	return
 }
 
-// Exists returns a bool if a given object exists (utilizing the primary key columns if they exist) wrapped in a transaction.
-func (i *Invocation) Exists(object DatabaseMapped) (exists bool, err error) {
+// settableElement returns a pointer to a slice element that its fields can be set through.
+// Elements that are struct values held in interfaces cannot be set and return false.
+func settableElement(element reflect.Value) (interface{}, bool) {
+	for element.Kind() == reflect.Interface && !element.IsNil() {
+		element = element.Elem()
+	}
+	switch element.Kind() {
+	case reflect.Ptr:
+		return element.Interface(), !element.IsNil()
+	case reflect.Struct:
+		if element.CanAddr() {
+			return element.Addr().Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// CopyMany writes many objects to the database with `COPY ... FROM STDIN`, which is much faster than
+// `CreateMany(...)` for large batches and has no limit on the number of rows.
+// Serial and read only columns are left to the database, and serial values are not read back into the objects.
+// COPY requires a transaction; if the invocation is not in one, one is opened and committed for the copy.
+func (i *Invocation) CopyMany(objects interface{}) (err error) {
 	err = i.check()
 	if err != nil {
 		return
@@ -615,36 +921,420 @@ func (i *Invocation) Exists(object DatabaseMapped) (exists bool, err error) {
 
 	var queryBody string
 	start := time.Now()
-	defer func() { err = i.panicHandler(recover(), err, EventFlagQuery, queryBody, start) }()
+	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
 
-	tableName := object.TableName()
-	if len(i.statementLabel) == 0 {
-		i.statementLabel = fmt.Sprintf("%s_exists", tableName)
+	sliceValue := reflectValue(objects)
+	if sliceValue.Len() == 0 {
+		return nil
 	}
-	cols := getCachedColumnCollectionFromInstance(object)
-	pks := cols.PrimaryKeys()
 
-	if pks.Len() == 0 {
-		exists = false
-		err = exception.New("No primary key on object.")
+	sliceType := reflectSliceType(objects)
+	tableName, err := TableName(sliceType)
+	if err != nil {
 		return
 	}
 
-	queryBodyBuffer := i.db.conn.bufferPool.Get()
-	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
-
-	queryBodyBuffer.WriteString("SELECT 1 FROM ")
-	queryBodyBuffer.WriteString(tableName)
-	queryBodyBuffer.WriteString(" WHERE ")
-
-	for i, pk := range pks.Columns() {
-		queryBodyBuffer.WriteString(pk.ColumnName)
-		queryBodyBuffer.WriteString(" = ")
-		queryBodyBuffer.WriteString("$" + strconv.Itoa(i+1))
-
-		if i < (pks.Len() - 1) {
-			queryBodyBuffer.WriteString(" AND ")
-		}
+	if err = runHooks(i.db, sliceValue, hookBeforeCreate); err != nil {
+		return
+	}
+
+	cols := getCachedColumnCollectionFromType(newColumnCacheKey(sliceType), sliceType)
+	if err = cols.Err(); err != nil {
+		return
+	}
+	writeCols := cols.NotReadOnly().NotSerials()
+	if err = validateMany(sliceValue, writeCols); err != nil {
+		return
+	}
+	queryBody = pq.CopyIn(tableName, writeCols.ColumnNames()...)
+
+	tx := i.db.tx
+	if tx == nil {
+		tx, err = i.db.conn.BeginContext(i.Context())
+		if err != nil {
+			err = exception.Wrap(err)
+			return
+		}
+		defer func() {
+			if err != nil {
+				tx.Rollback()
+				return
+			}
+			if commitErr := tx.Commit(); commitErr != nil {
+				err = exception.Wrap(commitErr)
+			}
+		}()
+	}
+
+	stmt, err := tx.PrepareContext(i.Context(), queryBody)
+	if err != nil {
+		err = exception.Wrap(err)
+		return
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			err = exception.Nest(err, closeErr)
+		}
+	}()
+
+	now := i.db.conn.Now()
+	for row := 0; row < sliceValue.Len(); row++ {
+		rowValues, valuesErr := writeCols.columnValues(sliceValue.Index(row).Interface())
+		if valuesErr != nil {
+			err = valuesErr
+			return
+		}
+		if err = stampValues(sliceValue.Index(row), writeCols, rowValues, now, true); err != nil {
+			err = exception.Wrap(err)
+			return
+		}
+		if _, err = stmt.ExecContext(i.Context(), rowValues...); err != nil {
+			err = exception.Wrap(err)
+			return
+		}
+	}
+
+	// an exec with no arguments flushes the buffered rows to the server.
+	if _, err = stmt.ExecContext(i.Context()); err != nil {
+		err = exception.Wrap(err)
+		return
+	}
+
+	// the after hooks run in the copy's transaction, even if the invocation wasn't in one.
+	err = runHooks(i.db.conn.DB().InTx(tx).WithContext(i.Context()), sliceValue, hookAfterCreate)
+	return
+}
+
+// Update updates an object wrapped in a transaction.
+func (i *Invocation) Update(object DatabaseMapped) (err error) {
+	_, err = i.UpdateWithRowsAffected(object)
+	return
+}
+
+// UpdateWithRowsAffected updates an object and returns the number of rows affected.
+func (i *Invocation) UpdateWithRowsAffected(object DatabaseMapped) (rowsAffected int64, err error) {
+	if err = runHook(i.db, object, hookBeforeUpdate); err != nil {
+		return
+	}
+	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
+	return i.update(object, cols.WriteColumns().NotVersions().NotAutoCreates(), "")
+}
+
+// UpdateColumns updates only the named columns of an object, leaving the rest of the row as it is.
+// The names must be writable columns of the object's type, i.e. not primary keys, serials, read only or version columns.
+//
+//	err := spiffy.Default().DB().Invoke().UpdateColumns(&user, "status", "updated_utc")
+func (i *Invocation) UpdateColumns(object DatabaseMapped, columnNames ...string) (err error) {
+	if len(columnNames) == 0 {
+		return exception.New("invalid `columnNames` parameter; at least one column is required.")
+	}
+
+	if err = runHook(i.db, object, hookBeforeUpdate); err != nil {
+		return
+	}
+
+	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
+	writeCols := cols.WriteColumns().NotVersions()
+	lookup := writeCols.Lookup()
+
+	names := map[string]bool{}
+	for _, name := range columnNames {
+		if _, hasColumn := lookup[name]; !hasColumn {
+			return exception.Newf("column `%s` is not a writable column of `%s`.", name, object.TableName())
+		}
+		names[name] = true
+	}
+
+	var updateCols []Column
+	for _, col := range writeCols.Columns() {
+		if names[col.ColumnName] {
+			updateCols = append(updateCols, col)
+		}
+	}
+
+	_, err = i.update(object, newColumnCollectionFromColumns(updateCols), "columns")
+	return
+}
+
+// UpdateChanged updates only the columns of an object that have changed since it was read.
+// The object's type must embed a `ChangeTracker`; if it does not, or the object was not read (or written) through
+// spiffy, every column is written as with `Update`. If nothing has changed no statement is run.
+func (i *Invocation) UpdateChanged(object DatabaseMapped) (err error) {
+	// the hook runs before the diff so that fields it derives are written too.
+	if err = runHook(i.db, object, hookBeforeUpdate); err != nil {
+		return
+	}
+
+	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
+	writeCols := cols.WriteColumns().NotVersions().NotAutoCreates()
+
+	changed, isTracked := changedColumns(object, writeCols)
+	if !isTracked {
+		_, err = i.update(object, writeCols, "")
+		return
+	}
+	if changed.Len() == 0 {
+		return i.check()
+	}
+	_, err = i.update(object, changed, "changed")
+	return
+}
+
+// update updates a set of columns of an object, matching on its primary keys (and version, if it has one).
+// If the label suffix is set the default statement label is suffixed with it and the column names,
+// so that statements for different sets of columns are cached separately.
+func (i *Invocation) update(object DatabaseMapped, writeCols *ColumnCollection, labelSuffix string) (rowsAffected int64, err error) {
+	err = i.check()
+	if err != nil {
+		return
+	}
+
+	var queryBody string
+	start := time.Now()
+	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
+
+	tableName := object.TableName()
+	if len(i.statementLabel) == 0 {
+		if len(labelSuffix) > 0 {
+			// the columns are joined with a comma, which can't appear in a column name, so each set of columns gets its own label.
+			i.statementLabel = fmt.Sprintf("%s_update_%s_%s", tableName, labelSuffix, CSV(writeCols.ColumnNames()))
+		} else {
+			i.statementLabel = fmt.Sprintf("%s_update", tableName)
+		}
+	}
+
+	cols := getCachedColumnCollectionFromInstance(object)
+	pks := cols.PrimaryKeys()
+	version := cols.Versions().FirstOrDefault()
+
+	// automatic update timestamps are written by every update, even of a subset of the columns.
+	for _, col := range cols.AutoUpdates().Columns() {
+		if !writeCols.HasColumn(col.ColumnName) {
+			writeCols = writeCols.ConcatWith(newColumnCollectionFromColumns([]Column{col}))
+		}
+	}
+
+	if err = validate(object, writeCols); err != nil {
+		return
+	}
+
+	updateValues, valuesErr := writeCols.columnValues(object)
+	if valuesErr != nil {
+		err = valuesErr
+		return
+	}
+	if err = stampValues(reflect.ValueOf(object), writeCols, updateValues, i.db.conn.Now(), false); err != nil {
+		err = exception.Wrap(err)
+		return
+	}
+	updateValues = append(updateValues, pks.ColumnValues(object)...)
+	numColumns := writeCols.Len()
+
+	queryBodyBuffer := i.db.conn.bufferPool.Get()
+	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
+
+	queryBodyBuffer.WriteString("UPDATE ")
+	queryBodyBuffer.WriteString(tableName)
+	queryBodyBuffer.WriteString(" SET ")
+
+	var writeColIndex int
+	var col Column
+	for ; writeColIndex < writeCols.Len(); writeColIndex++ {
+		col = writeCols.columns[writeColIndex]
+		queryBodyBuffer.WriteString(col.ColumnName)
+		queryBodyBuffer.WriteString(" = $" + strconv.Itoa(writeColIndex+1))
+		if writeColIndex != numColumns-1 {
+			queryBodyBuffer.WriteRune(runeComma)
+		}
+	}
+
+	if version != nil {
+		if numColumns > 0 {
+			queryBodyBuffer.WriteRune(runeComma)
+		}
+		queryBodyBuffer.WriteString(version.ColumnName + " = " + version.ColumnName + " + 1")
+	}
+
+	queryBodyBuffer.WriteString(" WHERE ")
+	for i, pk := range pks.Columns() {
+		queryBodyBuffer.WriteString(pk.ColumnName)
+		queryBodyBuffer.WriteString(" = ")
+		queryBodyBuffer.WriteString("$" + strconv.Itoa(i+(writeColIndex+1)))
+
+		if i < (pks.Len() - 1) {
+			queryBodyBuffer.WriteString(" AND ")
+		}
+	}
+
+	if version != nil {
+		updateValues = append(updateValues, version.GetValue(object))
+		queryBodyBuffer.WriteString(" AND " + version.ColumnName + " = $" + strconv.Itoa(len(updateValues)))
+		queryBodyBuffer.WriteString(" RETURNING " + version.ColumnName)
+	}
+
+	queryBody = queryBodyBuffer.String()
+	stmt, stmtErr := i.Prepare(queryBody)
+	if stmtErr != nil {
+		err = exception.Wrap(stmtErr)
+		return
+	}
+
+	defer func() { err = i.closeStatement(err, stmt) }()
+
+	if version != nil {
+		rowsAffected, err = i.scanVersion(stmt, object, version, updateValues)
+	} else {
+		res, execErr := stmt.ExecContext(i.Context(), updateValues...)
+		if execErr != nil {
+			err = exception.Wrap(execErr)
+			i.invalidateCachedStatement()
+			return
+		}
+		rowsAffected, err = i.checkRowsAffected(res)
+	}
+
+	if err == nil {
+		takeSnapshot(object, writeCols)
+		err = runHook(i.db, object, hookAfterUpdate)
+	}
+	return
+}
+
+// UpdateWhere sets the (non-primary key, non-serial, non-readonly) columns of every row matching a where clause
+// to the values of an object, returning the number of rows affected.
+// Version columns are incremented on each matched row rather than set from the object,
+// and soft delete columns are left as they are; use `DeleteWhere` to set them.
+// Parameters in the where clause are numbered from `$1`.
+func (i *Invocation) UpdateWhere(object DatabaseMapped, where string, args ...interface{}) (rowsAffected int64, err error) {
+	err = i.check()
+	if err != nil {
+		return
+	}
+
+	var queryBody string
+	start := time.Now()
+	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
+
+	if len(where) == 0 {
+		err = exception.New("a where clause is required, use `Update` to update by primary key.")
+		return
+	}
+
+	tableName := object.TableName()
+	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
+	writeCols := cols.WriteColumns().NotVersions().NotAutoCreates().NotSoftDeletes()
+	writeValues, valuesErr := writeCols.columnValues(object)
+	if valuesErr != nil {
+		err = valuesErr
+		return
+	}
+	if err = stampValues(reflect.ValueOf(object), writeCols, writeValues, i.db.conn.Now(), false); err != nil {
+		err = exception.Wrap(err)
+		return
+	}
+
+	queryBodyBuffer := i.db.conn.bufferPool.Get()
+	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
+
+	queryBodyBuffer.WriteString("UPDATE ")
+	queryBodyBuffer.WriteString(tableName)
+	queryBodyBuffer.WriteString(" SET ")
+	for x, col := range writeCols.Columns() {
+		queryBodyBuffer.WriteString(col.ColumnName)
+		queryBodyBuffer.WriteString(" = $" + strconv.Itoa(x+1))
+		if x < writeCols.Len()-1 {
+			queryBodyBuffer.WriteRune(runeComma)
+		}
+	}
+	// each matched row keeps its own version, incremented, rather than taking the object's.
+	for x, version := range cols.Versions().Columns() {
+		if x > 0 || writeCols.Len() > 0 {
+			queryBodyBuffer.WriteRune(runeComma)
+		}
+		queryBodyBuffer.WriteString(version.ColumnName + " = " + version.ColumnName + " + 1")
+	}
+	queryBodyBuffer.WriteString(" WHERE ")
+	queryBodyBuffer.WriteString(renumberParams(where, writeCols.Len()))
+
+	queryBody = queryBodyBuffer.String()
+	stmt, stmtErr := i.Prepare(queryBody)
+	if stmtErr != nil {
+		err = exception.Wrap(stmtErr)
+		return
+	}
+	defer func() { err = i.closeStatement(err, stmt) }()
+
+	res, execErr := stmt.ExecContext(i.Context(), append(writeValues, args...)...)
+	if execErr != nil {
+		err = exception.Wrap(execErr)
+		i.invalidateCachedStatement()
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	err = exception.Wrap(err)
+	return
+}
+
+// Exists returns a bool if a given object exists (utilizing the primary key columns if they exist) wrapped in a transaction.
+func (i *Invocation) Exists(object DatabaseMapped) (exists bool, err error) {
+	err = i.check()
+	if err != nil {
+		return
+	}
+
+	var queryBody string
+	start := time.Now()
+	defer func() { err = i.panicHandler(recover(), err, EventFlagQuery, queryBody, start) }()
+
+	tableName := object.TableName()
+	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
+	pks := cols.PrimaryKeys()
+	softDelete := i.scopeColumn(cols)
+	if len(i.statementLabel) == 0 {
+		i.statementLabel = fmt.Sprintf("%s_exists%s", tableName, i.scopeLabel(cols))
+	}
+
+	if pks.Len() == 0 {
+		exists = false
+		err = exception.New("No primary key on object.")
+		return
+	}
+
+	queryBodyBuffer := i.db.conn.bufferPool.Get()
+	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
+
+	queryBodyBuffer.WriteString("SELECT 1 FROM ")
+	queryBodyBuffer.WriteString(tableName)
+	queryBodyBuffer.WriteString(" WHERE ")
+
+	for i, pk := range pks.Columns() {
+		queryBodyBuffer.WriteString(pk.ColumnName)
+		queryBodyBuffer.WriteString(" = ")
+		queryBodyBuffer.WriteString("$" + strconv.Itoa(i+1))
+
+		if i < (pks.Len() - 1) {
+			queryBodyBuffer.WriteString(" AND ")
+		}
+	}
+
+	if softDelete != nil {
+		queryBodyBuffer.WriteString(" AND " + softDelete.ColumnName + " IS NULL")
 	}
 
 	queryBody = queryBodyBuffer.String()
@@ -658,7 +1348,7 @@ func (i *Invocation) Exists(object DatabaseMapped) (exists bool, err error) {
 	defer func() { err = i.closeStatement(err, stmt) }()
 
 	pkValues := pks.ColumnValues(object)
-	rows, queryErr := stmt.Query(pkValues...)
+	rows, queryErr := stmt.QueryContext(i.Context(), pkValues...)
 	defer func() {
 		closeErr := rows.Close()
 		if closeErr != nil {
@@ -679,6 +1369,12 @@ func (i *Invocation) Exists(object DatabaseMapped) (exists bool, err error) {
 
 // Delete deletes an object from the database wrapped in a transaction.
 func (i *Invocation) Delete(object DatabaseMapped) (err error) {
+	_, err = i.DeleteWithRowsAffected(object)
+	return
+}
+
+// DeleteWithRowsAffected deletes an object from the database and returns the number of rows affected.
+func (i *Invocation) DeleteWithRowsAffected(object DatabaseMapped) (rowsAffected int64, err error) {
 	err = i.check()
 	if err != nil {
 		return
@@ -688,14 +1384,25 @@ func (i *Invocation) Delete(object DatabaseMapped) (err error) {
 	start := time.Now()
 	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
 
-	tableName := object.TableName()
-
-	if len(i.statementLabel) == 0 {
-		i.statementLabel = fmt.Sprintf("%s_delete", tableName)
+	if err = runHook(i.db, object, hookBeforeDelete); err != nil {
+		return
 	}
 
+	tableName := object.TableName()
 	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
 	pks := cols.PrimaryKeys()
+	softDelete := i.softDeleteColumn(cols)
+
+	if len(i.statementLabel) == 0 {
+		if softDelete != nil {
+			i.statementLabel = fmt.Sprintf("%s_soft_delete", tableName)
+		} else {
+			i.statementLabel = fmt.Sprintf("%s_delete", tableName)
+		}
+	}
 
 	if len(pks.Columns()) == 0 {
 		err = exception.New("No primary key on object.")
@@ -705,8 +1412,14 @@ func (i *Invocation) Delete(object DatabaseMapped) (err error) {
 	queryBodyBuffer := i.db.conn.bufferPool.Get()
 	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
 
-	queryBodyBuffer.WriteString("DELETE FROM ")
-	queryBodyBuffer.WriteString(tableName)
+	if softDelete != nil {
+		queryBodyBuffer.WriteString("UPDATE ")
+		queryBodyBuffer.WriteString(tableName)
+		queryBodyBuffer.WriteString(" SET " + softDelete.ColumnName + " = $" + strconv.Itoa(pks.Len()+1))
+	} else {
+		queryBodyBuffer.WriteString("DELETE FROM ")
+		queryBodyBuffer.WriteString(tableName)
+	}
 	queryBodyBuffer.WriteString(" WHERE ")
 
 	for i, pk := range pks.Columns() {
@@ -719,6 +1432,10 @@ func (i *Invocation) Delete(object DatabaseMapped) (err error) {
 		}
 	}
 
+	if softDelete != nil {
+		queryBodyBuffer.WriteString(" AND " + softDelete.ColumnName + " IS NULL")
+	}
+
 	queryBody = queryBodyBuffer.String()
 	stmt, stmtErr := i.Prepare(queryBody)
 	if stmtErr != nil {
@@ -729,16 +1446,32 @@ func (i *Invocation) Delete(object DatabaseMapped) (err error) {
 
 	pkValues := pks.ColumnValues(object)
 
-	_, execErr := stmt.Exec(pkValues...)
+	var deletedAt time.Time
+	if softDelete != nil {
+		deletedAt = i.db.conn.Now()
+		pkValues = append(pkValues, deletedAt)
+	}
+
+	res, execErr := stmt.ExecContext(i.Context(), pkValues...)
 	if execErr != nil {
 		err = exception.Wrap(execErr)
 		i.invalidateCachedStatement()
+		return
+	}
+
+	rowsAffected, err = i.checkRowsAffected(res)
+	if err == nil && softDelete != nil && reflect.ValueOf(object).Kind() == reflect.Ptr {
+		err = exception.Wrap(softDelete.SetValue(object, &deletedAt))
+	}
+	if err == nil {
+		err = runHook(i.db, object, hookAfterDelete)
 	}
 	return
 }
 
-// Upsert inserts the object if it doesn't exist already (as defined by its primary keys) or updates it wrapped in a transaction.
-func (i *Invocation) Upsert(object DatabaseMapped) (err error) {
+// DeleteWhere deletes the rows of an object mapped table that match a where clause, returning the number of rows affected.
+// The object is only used to determine the table. Parameters in the where clause are numbered from `$1`.
+func (i *Invocation) DeleteWhere(object DatabaseMapped, where string, args ...interface{}) (rowsAffected int64, err error) {
 	err = i.check()
 	if err != nil {
 		return
@@ -748,107 +1481,312 @@ func (i *Invocation) Upsert(object DatabaseMapped) (err error) {
 	start := time.Now()
 	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
 
+	if len(where) == 0 {
+		err = exception.New("a where clause is required, use `Delete` to delete by primary key.")
+		return
+	}
+
+	queryBodyBuffer := i.db.conn.bufferPool.Get()
+	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
+
 	cols := getCachedColumnCollectionFromInstance(object)
-	writeCols := cols.NotReadOnly().NotSerials()
+	if err = cols.Err(); err != nil {
+		return
+	}
+	softDelete := i.softDeleteColumn(cols)
+	if softDelete != nil {
+		queryBodyBuffer.WriteString("UPDATE ")
+		queryBodyBuffer.WriteString(object.TableName())
+		queryBodyBuffer.WriteString(" SET " + softDelete.ColumnName + " = $" + strconv.Itoa(len(args)+1))
+		queryBodyBuffer.WriteString(" WHERE (" + where + ") AND " + softDelete.ColumnName + " IS NULL")
+		args = append(args, i.db.conn.Now())
+	} else {
+		queryBodyBuffer.WriteString("DELETE FROM ")
+		queryBodyBuffer.WriteString(object.TableName())
+		queryBodyBuffer.WriteString(" WHERE ")
+		queryBodyBuffer.WriteString(where)
+	}
 
-	conflictUpdateCols := cols.NotReadOnly().NotSerials().NotPrimaryKeys()
+	queryBody = queryBodyBuffer.String()
+	stmt, stmtErr := i.Prepare(queryBody)
+	if stmtErr != nil {
+		err = exception.Wrap(stmtErr)
+		return
+	}
+	defer func() { err = i.closeStatement(err, stmt) }()
 
-	serials := cols.Serials()
-	pks := cols.PrimaryKeys()
+	res, execErr := stmt.ExecContext(i.Context(), args...)
+	if execErr != nil {
+		err = exception.Wrap(execErr)
+		i.invalidateCachedStatement()
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	err = exception.Wrap(err)
+	return
+}
+
+// Upsert inserts the object if it doesn't exist already (as defined by its primary keys) or updates it wrapped in a transaction.
+// Options can change the conflict target, the columns that are updated, or skip conflicting rows; see `UpsertOptions`.
+func (i *Invocation) Upsert(object DatabaseMapped, opts ...UpsertOptions) (err error) {
+	err = i.check()
+	if err != nil {
+		return
+	}
+
+	var queryBody string
+	start := time.Now()
+	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
+
+	options := OptionalUpsertOptions(opts...)
+	cols := getCachedColumnCollectionFromInstance(object)
+	if err = cols.Err(); err != nil {
+		return
+	}
+	writeCols := cols.NotReadOnly().NotSerials()
+	if err = validate(object, writeCols); err != nil {
+		return
+	}
+	version := cols.Versions().FirstOrDefault()
+	returning := upsertReturning(cols)
 	tableName := object.TableName()
 
-	if len(i.statementLabel) == 0 {
+	// only the default statement is the same every time, so only it can share a cached plan.
+	if len(i.statementLabel) == 0 && options.IsZero() {
 		i.statementLabel = fmt.Sprintf("%s_upsert", tableName)
 	}
 
-	colNames := writeCols.ColumnNames()
-	colValues := writeCols.ColumnValues(object)
+	queryBody, err = upsertStatement(tableName, cols, 1, options)
+	if err != nil {
+		return
+	}
+	colValues, valuesErr := writeCols.columnValues(object)
+	if valuesErr != nil {
+		err = valuesErr
+		return
+	}
+	if err = stampValues(reflect.ValueOf(object), writeCols, colValues, i.db.conn.Now(), true); err != nil {
+		err = exception.Wrap(err)
+		return
+	}
+	colValues = append(colValues, options.WhereArgs...)
 
-	queryBodyBuffer := i.db.conn.bufferPool.Get()
-	defer i.db.conn.bufferPool.Put(queryBodyBuffer)
+	stmt, stmtErr := i.Prepare(queryBody)
+	if stmtErr != nil {
+		err = exception.Wrap(stmtErr)
+		return
+	}
+	defer func() { err = i.closeStatement(err, stmt) }()
 
-	queryBodyBuffer.WriteString("INSERT INTO ")
-	queryBodyBuffer.WriteString(tableName)
-	queryBodyBuffer.WriteString(" (")
-	for i, name := range colNames {
-		queryBodyBuffer.WriteString(name)
-		if i < len(colNames)-1 {
-			queryBodyBuffer.WriteRune(runeComma)
+	if len(returning) == 0 {
+		_, execErr := stmt.ExecContext(i.Context(), colValues...)
+		if execErr != nil {
+			err = exception.Wrap(execErr)
+			return
 		}
+		return nil
 	}
-	queryBodyBuffer.WriteString(") VALUES (")
 
-	for x := 0; x < writeCols.Len(); x++ {
-		queryBodyBuffer.WriteString("$" + strconv.Itoa(x+1))
-		if x < (writeCols.Len() - 1) {
-			queryBodyBuffer.WriteRune(runeComma)
+	values := make([]interface{}, len(returning))
+	targets := make([]interface{}, len(returning))
+	for x := range values {
+		targets[x] = &values[x]
+	}
+
+	execErr := stmt.QueryRowContext(i.Context(), colValues...).Scan(targets...)
+	if execErr == sql.ErrNoRows {
+		// the row conflicted and was not updated, either because of a version mismatch or the options.
+		if version != nil && !options.DoNothing && len(options.Where) == 0 {
+			err = newVersionConflictError(tableName, version, version.GetValue(object))
 		}
+		return
+	}
+	if execErr != nil {
+		err = exception.Wrap(execErr)
+		i.invalidateCachedStatement()
+		return
 	}
 
-	queryBodyBuffer.WriteString(")")
+	for x, col := range returning {
+		if setErr := col.SetValue(object, values[x]); setErr != nil {
+			err = exception.Wrap(setErr)
+			return
+		}
+	}
+	return nil
+}
 
-	if pks.Len() > 0 {
-		tokenMap := map[string]string{}
-		for i, col := range writeCols.Columns() {
-			tokenMap[col.ColumnName] = "$" + strconv.Itoa(i+1)
+// UpsertMany inserts or updates many objects, in chunks that fit within the statement parameter limit.
+// Serial and version values are set on the elements in input order when every row is returned;
+// when conflicting rows are skipped (`DoNothing` or a `Where` on the update) the elements are left as they are.
+// If the type has a version column and fewer rows are returned with neither option set, a `*VersionConflictError` is returned.
+// If the objects are split into chunks and the invocation is not in a transaction, the chunks run in one, so an error or conflict writes none of them.
+func (i *Invocation) UpsertMany(objects interface{}, opts ...UpsertOptions) (err error) {
+	err = i.check()
+	if err != nil {
+		return
+	}
+
+	var queryBody string
+	start := time.Now()
+	defer func() { err = i.panicHandler(recover(), err, EventFlagExecute, queryBody, start) }()
+
+	sliceValue := reflectValue(objects)
+	if sliceValue.Len() == 0 {
+		return nil
+	}
+
+	sliceType := reflectSliceType(objects)
+	tableName, err := TableName(sliceType)
+	if err != nil {
+		return
+	}
+
+	options := OptionalUpsertOptions(opts...)
+	cols := getCachedColumnCollectionFromType(newColumnCacheKey(sliceType), sliceType)
+	if err = cols.Err(); err != nil {
+		return
+	}
+	writeCols := cols.NotReadOnly().NotSerials()
+	if err = validateMany(sliceValue, writeCols); err != nil {
+		return
+	}
+	version := cols.Versions().FirstOrDefault()
+	returning := upsertReturning(cols)
+
+	chunkSize := sliceValue.Len()
+	if paramCount := writeCols.Len(); paramCount > 0 && chunkSize*paramCount+len(options.WhereArgs) > maxStatementParameters {
+		chunkSize = (maxStatementParameters - len(options.WhereArgs)) / paramCount
+	}
+
+	// as with `CreateMany`, several chunks run in a transaction so a failed or conflicting chunk
+	// doesn't leave the ones before it committed.
+	chunks := i
+	if i.db.tx == nil && chunkSize < sliceValue.Len() {
+		tx, txErr := i.db.conn.BeginContext(i.Context())
+		if txErr != nil {
+			err = exception.Wrap(txErr)
+			return
 		}
+		defer func() {
+			if err != nil {
+				tx.Rollback()
+				return
+			}
+			if commitErr := tx.Commit(); commitErr != nil {
+				err = exception.Wrap(commitErr)
+			}
+		}()
 
-		queryBodyBuffer.WriteString(" ON CONFLICT (")
-		pkColumnNames := pks.ColumnNames()
-		for i, name := range pkColumnNames {
-			queryBodyBuffer.WriteString(name)
-			if i < len(pkColumnNames)-1 {
-				queryBodyBuffer.WriteRune(runeComma)
+		inTx := *i
+		inTx.db = i.db.conn.DB().InTx(tx).WithContext(i.Context())
+		chunks = &inTx
+	}
+
+	for chunkStart := 0; chunkStart < sliceValue.Len(); chunkStart += chunkSize {
+		chunkEnd := chunkStart + chunkSize
+		if chunkEnd > sliceValue.Len() {
+			chunkEnd = sliceValue.Len()
+		}
+		chunk := sliceValue.Slice(chunkStart, chunkEnd)
+
+		queryBody, err = upsertStatement(tableName, cols, chunk.Len(), options)
+		if err != nil {
+			return
+		}
+
+		now := i.db.conn.Now()
+		var colValues []interface{}
+		for row := 0; row < chunk.Len(); row++ {
+			rowValues, valuesErr := writeCols.columnValues(chunk.Index(row).Interface())
+			if valuesErr != nil {
+				err = valuesErr
+				return
+			}
+			if err = stampValues(chunk.Index(row), writeCols, rowValues, now, true); err != nil {
+				err = exception.Wrap(err)
+				return
 			}
+			colValues = append(colValues, rowValues...)
 		}
-		queryBodyBuffer.WriteString(") DO UPDATE SET ")
+		colValues = append(colValues, options.WhereArgs...)
 
-		conflictCols := conflictUpdateCols.Columns()
-		for i, col := range conflictCols {
-			queryBodyBuffer.WriteString(col.ColumnName + " = " + tokenMap[col.ColumnName])
-			if i < (len(conflictCols) - 1) {
-				queryBodyBuffer.WriteRune(runeComma)
+		var returned [][]interface{}
+		returned, err = chunks.queryReturning(queryBody, len(returning), colValues)
+		if err != nil {
+			return
+		}
+
+		if len(returning) == 0 {
+			continue
+		}
+		if len(returned) < chunk.Len() {
+			if version != nil && !options.DoNothing && len(options.Where) == 0 {
+				err = newVersionConflictError(tableName, version, nil)
+				return
 			}
+			continue
 		}
-	}
 
-	var serial = serials.FirstOrDefault()
-	if serials.Len() != 0 {
-		queryBodyBuffer.WriteString(" RETURNING ")
-		queryBodyBuffer.WriteString(serial.ColumnName)
+		for row, values := range returned {
+			target, isSettable := settableElement(chunk.Index(row))
+			if !isSettable {
+				continue
+			}
+			for x, col := range returning {
+				if err = col.SetValue(target, values[x]); err != nil {
+					err = exception.Wrap(err)
+					return
+				}
+			}
+		}
 	}
+	return nil
+}
 
-	queryBody = queryBodyBuffer.String()
-
-	stmt, stmtErr := i.Prepare(queryBody)
+// queryReturning runs a statement and reads a number of returned columns from each row.
+// If the column count is zero the statement is executed without reading any rows.
+func (i *Invocation) queryReturning(statement string, columnCount int, args []interface{}) (returned [][]interface{}, err error) {
+	stmt, stmtErr := i.Prepare(statement)
 	if stmtErr != nil {
 		err = exception.Wrap(stmtErr)
 		return
 	}
 	defer func() { err = i.closeStatement(err, stmt) }()
 
-	if serials.Len() != 0 {
-		var id interface{}
-		execErr := stmt.QueryRow(colValues...).Scan(&id)
-		if execErr != nil {
+	if columnCount == 0 {
+		if _, execErr := stmt.ExecContext(i.Context(), args...); execErr != nil {
 			err = exception.Wrap(execErr)
-			i.invalidateCachedStatement()
-			return
 		}
-		setErr := serial.SetValue(object, id)
-		if setErr != nil {
-			err = exception.Wrap(setErr)
-			return
+		return
+	}
+
+	rows, queryErr := stmt.QueryContext(i.Context(), args...)
+	if queryErr != nil {
+		err = exception.Wrap(queryErr)
+		return
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			err = exception.Nest(err, closeErr)
 		}
-	} else {
-		_, execErr := stmt.Exec(colValues...)
-		if execErr != nil {
-			err = exception.Wrap(execErr)
+	}()
+
+	for rows.Next() {
+		values := make([]interface{}, columnCount)
+		targets := make([]interface{}, columnCount)
+		for x := range values {
+			targets[x] = &values[x]
+		}
+		if err = rows.Scan(targets...); err != nil {
+			err = exception.Wrap(err)
 			return
 		}
+		returned = append(returned, values)
 	}
-
-	return nil
+	err = exception.Wrap(rows.Err())
+	return
 }
 
 // --------------------------------------------------------------------------------
@@ -868,6 +1806,61 @@ func (i *Invocation) check() error {
 	return nil
 }
 
+// scopeColumn returns the soft delete column reads should exclude rows by, unless the invocation is unscoped.
+func (i *Invocation) scopeColumn(cols *ColumnCollection) *Column {
+	if i.unscoped {
+		return nil
+	}
+	return cols.SoftDeletes().FirstOrDefault()
+}
+
+// scopeLabel returns a suffix for default statement labels of unscoped reads of soft deleted types,
+// so that they are cached separately from the scoped statements.
+func (i *Invocation) scopeLabel(cols *ColumnCollection) string {
+	if i.unscoped && cols.SoftDeletes().Len() > 0 {
+		return "_unscoped"
+	}
+	return ""
+}
+
+// softDeleteColumn returns the column deletes should set instead of removing rows, unless the invocation is a hard delete.
+func (i *Invocation) softDeleteColumn(cols *ColumnCollection) *Column {
+	if i.hardDelete {
+		return nil
+	}
+	return cols.SoftDeletes().FirstOrDefault()
+}
+
+// checkRowsAffected returns the rows affected by a result, and `ErrNoRowsAffected` if required and none were.
+func (i *Invocation) checkRowsAffected(res sql.Result) (int64, error) {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, exception.Wrap(err)
+	}
+	if rowsAffected == 0 && i.requireRowsAffected {
+		return 0, ErrNoRowsAffected
+	}
+	return rowsAffected, nil
+}
+
+// scanVersion runs an update that returns the new value of a version column and writes it back to the object.
+// If no row is returned the version did not match and a `*VersionConflictError` is returned.
+func (i *Invocation) scanVersion(stmt *sql.Stmt, object DatabaseMapped, version *Column, args []interface{}) (int64, error) {
+	var newVersion interface{}
+	err := stmt.QueryRowContext(i.Context(), args...).Scan(&newVersion)
+	if err == sql.ErrNoRows {
+		return 0, newVersionConflictError(object.TableName(), version, version.GetValue(object))
+	}
+	if err != nil {
+		i.invalidateCachedStatement()
+		return 0, exception.Wrap(err)
+	}
+	if err = version.SetValue(object, newVersion); err != nil {
+		return 0, exception.Wrap(err)
+	}
+	return 1, nil
+}
+
 func (i *Invocation) invalidateCachedStatement() {
 	if i.db.conn.useStatementCache && len(i.statementLabel) > 0 {
 		i.db.conn.statementCache.InvalidateStatement(i.statementLabel)
@@ -890,6 +1883,7 @@ func (i *Invocation) panicHandler(r interface{}, err error, eventFlag logger.Eve
 		recoveryException := exception.New(r)
 		return exception.Nest(err, recoveryException)
 	}
+	err = contextCanceledError(i.ctx, err, statement)
 	if i.fireEvents {
 		i.db.conn.fireEvent(eventFlag, statement, time.Now().Sub(start), err, i.statementLabel)
 	}
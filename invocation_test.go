@@ -1,8 +1,10 @@
 package spiffy
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	assert "github.com/blendlabs/go-assert"
 )
@@ -29,3 +31,71 @@ func TestInvocationPrepare(t *testing.T) {
 	_, err := inv.Prepare("select 'ok!'")
 	assert.NotNil(err)
 }
+
+func TestInvocationWithContextCanceled(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Default().DB().WithContext(ctx).Invoke().Exec("select pg_sleep(1)")
+	assert.NotNil(err)
+	assert.True(IsContextCanceled(err))
+}
+
+func TestInvocationWithContextDeadline(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var ok string
+	err := Default().DB().Invoke().WithContext(ctx).Query("select pg_sleep(1)::text").Scan(&ok)
+	assert.NotNil(err)
+	assert.True(IsContextCanceled(err))
+}
+
+func TestInvocationExecWithResult(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(seedObjects(10, tx))
+
+	res, err := Default().DB().InTx(tx).Invoke().ExecWithResult("update bench_object set pending = $1 where category = $2", true, "category_1")
+	assert.Nil(err)
+	rowsAffected, err := res.RowsAffected()
+	assert.Nil(err)
+	assert.Equal(int64(1), rowsAffected)
+}
+
+func TestInvocationRequireRowsAffected(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createTable(tx))
+
+	obj := &benchObj{Name: "test_object_0", Timestamp: time.Now().UTC(), Category: "category_0"}
+	assert.Nil(Default().DB().InTx(tx).Invoke().Create(obj))
+
+	rowsAffected, err := Default().DB().InTx(tx).Invoke().UpdateWithRowsAffected(obj)
+	assert.Nil(err)
+	assert.Equal(int64(1), rowsAffected)
+
+	missing := &benchObj{ID: -1, Name: "missing"}
+	err = Default().DB().InTx(tx).Invoke().Update(missing)
+	assert.Nil(err)
+
+	err = Default().DB().InTx(tx).Invoke().RequireRowsAffected().Update(missing)
+	assert.True(IsNoRowsAffected(err))
+
+	err = Default().DB().InTx(tx).Invoke().RequireRowsAffected().Delete(missing)
+	assert.True(IsNoRowsAffected(err))
+
+	rowsAffected, err = Default().DB().InTx(tx).Invoke().RequireRowsAffected().DeleteWithRowsAffected(obj)
+	assert.Nil(err)
+	assert.Equal(int64(1), rowsAffected)
+}
@@ -0,0 +1,129 @@
+package spiffy
+
+import (
+	"bytes"
+	"database/sql"
+	"reflect"
+
+	exception "github.com/blendlabs/go-exception"
+	"github.com/lib/pq"
+)
+
+// JoinTarget maps the columns of a joined query that start with a prefix onto a database mapped type.
+// For `OutJoined` the target is a reference to a slice, and for `EachJoined` it is an instance of the type.
+type JoinTarget struct {
+	Target interface{}
+	Prefix string
+}
+
+// Prefixed returns a join target for the columns selected with a given prefix, i.e. with
+// `Columns(User{}).CopyWithColumnPrefix("u_").ColumnNamesCSVFromAlias("u")`.
+func Prefixed(target interface{}, prefix string) JoinTarget {
+	return JoinTarget{Target: target, Prefix: prefix}
+}
+
+// joinMapping is the column metadata for reading the rows of a joined query into several types.
+type joinMapping struct {
+	types       []reflect.Type
+	lookups     []map[string]*Column
+	primaryKeys []*ColumnCollection
+}
+
+// newJoinMapping reads the column metadata of a set of join targets of the given types.
+func newJoinMapping(targets []JoinTarget, types []reflect.Type) (*joinMapping, error) {
+	jm := joinMapping{
+		types:       types,
+		lookups:     make([]map[string]*Column, len(targets)),
+		primaryKeys: make([]*ColumnCollection, len(targets)),
+	}
+	for x, target := range targets {
+		if types[x].Kind() != reflect.Struct {
+			return nil, exception.Newf("join target `%s` is not a struct.", types[x].String())
+		}
+		meta := getCachedColumnCollectionFromType(newColumnCacheKey(types[x]), types[x])
+		if err := meta.Err(); err != nil {
+			return nil, err
+		}
+		jm.lookups[x] = meta.CopyWithColumnPrefix(target.Prefix).Lookup()
+		jm.primaryKeys[x] = meta.PrimaryKeys()
+	}
+	return &jm, nil
+}
+
+// populate reads a row into a new object per target. Targets that have no non-null columns in the row,
+// i.e. the unmatched side of an outer join, are nil.
+func (jm *joinMapping) populate(rows *sql.Rows) ([]interface{}, error) {
+	rowColumns, err := rows.Columns()
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+
+	values := make([]interface{}, len(rowColumns))
+	columns := make([]*Column, len(rowColumns))
+	targets := make([]int, len(rowColumns))
+	for x, name := range rowColumns {
+		targets[x] = -1
+		for y, lookup := range jm.lookups {
+			if col, hasColumn := lookup[name]; hasColumn {
+				columns[x] = col
+				targets[x] = y
+				break
+			}
+		}
+
+		if columns[x] == nil {
+			var value interface{}
+			values[x] = &value
+		} else {
+			values[x] = columns[x].scanTarget()
+		}
+	}
+
+	if err = rows.Scan(values...); err != nil {
+		return nil, exception.Wrap(err)
+	}
+
+	objects := make([]interface{}, len(jm.types))
+	for x, value := range values {
+		if targets[x] < 0 || isNullScanTarget(value) {
+			continue
+		}
+		object := objects[targets[x]]
+		if object == nil {
+			object = makeNew(jm.types[targets[x]])
+			objects[targets[x]] = object
+		}
+		if err = columns[x].SetValue(object, value); err != nil {
+			return nil, exception.Wrap(err)
+		}
+	}
+	return objects, nil
+}
+
+// key returns the primary key values of an object read for a target, if the target's type has primary keys.
+func (jm *joinMapping) key(target int, object interface{}) (string, bool) {
+	pks := jm.primaryKeys[target]
+	if pks.Len() == 0 {
+		return "", false
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	for _, value := range pks.ColumnValues(object) {
+		key, _, _ := relationKey(value)
+		buffer.WriteString(key + ";")
+	}
+	return buffer.String(), true
+}
+
+// isNullScanTarget returns if a scan destination read a null.
+func isNullScanTarget(target interface{}) bool {
+	if generic, isGeneric := target.(*pq.GenericArray); isGeneric {
+		target = generic.A
+	}
+	value := reflect.ValueOf(target).Elem()
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return value.IsNil()
+	}
+	return false
+}
@@ -2,7 +2,6 @@ package spiffy
 
 import (
 	"database/sql"
-	"reflect"
 
 	exception "github.com/blendlabs/go-exception"
 )
@@ -20,12 +19,7 @@ func PopulateByName(object interface{}, row *sql.Rows, cols *ColumnCollection) e
 
 	for i, name := range rowColumns {
 		if col, ok := columnLookup[name]; ok {
-			if col.IsJSON {
-				str := ""
-				values[i] = &str
-			} else {
-				values[i] = reflect.New(reflect.PtrTo(col.FieldType)).Interface()
-			}
+			values[i] = col.scanTarget()
 		} else {
 			var value interface{}
 			values[i] = &value
@@ -44,7 +38,7 @@ func PopulateByName(object interface{}, row *sql.Rows, cols *ColumnCollection) e
 		if field, ok := columnLookup[colName]; ok {
 			err := field.SetValue(object, v)
 			if err != nil {
-				return exception.Wrap(err)
+				return err
 			}
 		}
 	}
@@ -59,26 +53,7 @@ func PopulateInOrder(object DatabaseMapped, row *sql.Rows, cols *ColumnCollectio
 	var values = make([]interface{}, cols.Len())
 
 	for i, col := range cols.Columns() {
-		if col.FieldType.Kind() == reflect.Ptr {
-			if col.IsJSON {
-				str := ""
-				values[i] = &str
-			} else {
-				blankPtr := reflect.New(reflect.PtrTo(col.FieldType))
-				if blankPtr.CanAddr() {
-					values[i] = blankPtr.Addr()
-				} else {
-					values[i] = blankPtr.Interface()
-				}
-			}
-		} else {
-			if col.IsJSON {
-				str := ""
-				values[i] = &str
-			} else {
-				values[i] = reflect.New(reflect.PtrTo(col.FieldType)).Interface()
-			}
-		}
+		values[i] = col.scanTarget()
 	}
 
 	scanErr := row.Scan(values...)
@@ -92,7 +67,7 @@ func PopulateInOrder(object DatabaseMapped, row *sql.Rows, cols *ColumnCollectio
 		field := columns[i]
 		err := field.SetValue(object, v)
 		if err != nil {
-			return exception.Wrap(err)
+			return err
 		}
 	}
 
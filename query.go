@@ -1,8 +1,15 @@
 package spiffy
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/blendlabs/go-exception"
@@ -23,7 +30,30 @@ type Query struct {
 
 	stmt *sql.Stmt
 	db   *DB
+	ctx  context.Context
 	err  error
+
+	page       *offsetPage
+	keyset     *keysetPage
+	nextCursor string
+
+	cursorBatchSize int
+}
+
+// cursorCount is used to generate unique server-side cursor names.
+var cursorCount uint64
+
+// offsetPage is the state for a `LIMIT` and `OFFSET` paginated query.
+type offsetPage struct {
+	limit  int
+	offset int
+}
+
+// keysetPage is the state for a keyset paginated query.
+type keysetPage struct {
+	columns []*Column
+	after   []interface{}
+	limit   int
 }
 
 // Close closes and releases any resources retained by the QueryResult.
@@ -51,13 +81,116 @@ func (q *Query) CachedAs(cacheLabel string) *Query {
 	return q
 }
 
+// WithContext sets the context for the query.
+func (q *Query) WithContext(ctx context.Context) *Query {
+	q.ctx = ctx
+	return q
+}
+
+// Context returns the context for the query, defaulting to `context.Background()`.
+func (q *Query) Context() context.Context {
+	if q.ctx != nil {
+		return q.ctx
+	}
+	return context.Background()
+}
+
+// WithCursor reads the results of `Each(...)`, `EachObject(...)` and `OutMany(...)` through a server-side cursor,
+// fetching `batchSize` rows at a time instead of the full result set at once.
+// Cursors only exist within a transaction; if the query is not in one, a transaction is opened for the read and rolled back after it.
+func (q *Query) WithCursor(batchSize int) *Query {
+	if batchSize <= 0 {
+		q.err = exception.New("cursor batch size must be positive.")
+		return q
+	}
+	q.cursorBatchSize = batchSize
+	return q
+}
+
+// Page limits the query to a page of results using `LIMIT` and `OFFSET`.
+// The statement should have an `ORDER BY` clause for the pages to be stable.
+// The page is bound when the query runs, so calling it again replaces the page, as does calling `Keyset(...)`.
+func (q *Query) Page(limit, offset int) *Query {
+	q.page = &offsetPage{limit: limit, offset: offset}
+	q.keyset = nil
+	return q
+}
+
+// Keyset limits the query to the page of results after a cursor, ordered by a set of columns of a database mapped type.
+// If no columns are given the type's primary keys are used. An empty cursor returns the first page.
+// After `OutMany(...)` the cursor for the following page is available from `NextCursor()`.
+// Like `Page(...)` the keyset is bound when the query runs, and the last of the two to be called is used.
+//
+//	query := spiffy.Default().Query("select * from users where active = $1", true).Keyset(User{}, cursor, 50)
+//	err := query.OutMany(&users)
+//	next := query.NextCursor()
+func (q *Query) Keyset(object DatabaseMapped, cursor string, limit int, columns ...string) *Query {
+	if q.err != nil {
+		return q
+	}
+
+	cols := getCachedColumnCollectionFromInstance(object)
+	if err := cols.Err(); err != nil {
+		q.err = err
+		return q
+	}
+	if len(columns) == 0 {
+		columns = cols.PrimaryKeys().ColumnNames()
+	}
+	if len(columns) == 0 {
+		q.err = exception.New("keyset pagination requires ordering columns or a primary key.")
+		return q
+	}
+
+	lookup := cols.Lookup()
+	keyset := &keysetPage{limit: limit}
+	for _, name := range columns {
+		col, hasColumn := lookup[name]
+		if !hasColumn {
+			q.err = exception.Newf("keyset column `%s` is not mapped on `%s`.", name, object.TableName())
+			return q
+		}
+		keyset.columns = append(keyset.columns, col)
+	}
+
+	if len(cursor) > 0 {
+		values, err := decodeCursor(cursor)
+		if err != nil {
+			q.err = err
+			return q
+		}
+		if len(values) != len(columns) {
+			q.err = exception.New("keyset cursor does not match the ordering columns.")
+			return q
+		}
+		keyset.after = values
+	}
+
+	q.keyset = keyset
+	q.page = nil
+	return q
+}
+
+// NextCursor returns the cursor for the page following the results of a `Keyset(...)` query.
+// It is empty if the query returned fewer results than the page limit, i.e. if there are no more pages.
+func (q *Query) NextCursor() string {
+	return q.nextCursor
+}
+
 // Execute runs a given query, yielding the raw results.
 func (q *Query) Execute() (stmt *sql.Stmt, rows *sql.Rows, err error) {
+	if q.err != nil {
+		err = q.err
+		return
+	}
+
+	statement, args := q.boundStatement()
+
 	var stmtErr error
 	if q.shouldCacheStatement() {
-		stmt, stmtErr = q.db.conn.PrepareCached(q.statementLabel, q.statement, q.db.tx)
+		stmt, stmtErr = q.db.conn.PrepareCachedContext(q.Context(), q.statementLabel, statement, q.db.tx)
 	} else {
-		stmt, stmtErr = q.db.conn.Prepare(q.statement, q.db.tx)
+		stmt, stmtErr = q.db.conn.PrepareContext(q.Context(), statement, q.db.tx)
 	}
 
 	if stmtErr != nil {
@@ -79,7 +212,7 @@ func (q *Query) Execute() (stmt *sql.Stmt, rows *sql.Rows, err error) {
 	}()
 
 	var queryErr error
-	rows, queryErr = stmt.Query(q.args...)
+	rows, queryErr = stmt.QueryContext(q.Context(), args...)
 	if queryErr != nil {
 		if q.shouldCacheStatement() {
 			q.db.conn.statementCache.InvalidateStatement(q.statementLabel)
@@ -183,6 +316,9 @@ func (q *Query) Out(object interface{}) (err error) {
 	}
 
 	columnMeta := getCachedColumnCollectionFromInstance(object)
+	if err = columnMeta.Err(); err != nil {
+		return
+	}
 	var popErr error
 	if q.rows.Next() {
 		if populatable, isPopulatable := object.(Populatable); isPopulatable {
@@ -203,18 +339,6 @@ func (q *Query) Out(object interface{}) (err error) {
 func (q *Query) OutMany(collection interface{}) (err error) {
 	defer func() { err = q.panicHandler(recover(), err) }()
 
-	q.stmt, q.rows, q.err = q.Execute()
-	if q.err != nil {
-		err = exception.Wrap(q.err)
-		return err
-	}
-
-	rowsErr := q.rows.Err()
-	if rowsErr != nil {
-		err = exception.Wrap(rowsErr)
-		return
-	}
-
 	sliceType := reflectType(collection)
 	if sliceType.Kind() != reflect.Slice {
 		err = exception.New("destination collection is not a slice")
@@ -226,32 +350,42 @@ func (q *Query) OutMany(collection interface{}) (err error) {
 
 	v := makeNew(sliceInnerType)
 	meta := getCachedColumnCollectionFromType(newColumnCacheKey(sliceInnerType), sliceInnerType)
+	if err = meta.Err(); err != nil {
+		return
+	}
 
 	isPopulatable := isPopulatable(v)
 
-	var popErr error
 	didSetRows := false
-	for q.rows.Next() {
+	err = q.forEachRow(func(rows *sql.Rows) error {
 		newObj := makeNew(sliceInnerType)
 
+		var popErr error
 		if isPopulatable {
-			popErr = asPopulatable(newObj).Populate(q.rows)
+			popErr = asPopulatable(newObj).Populate(rows)
 		} else {
-			popErr = PopulateByName(newObj, q.rows, meta)
+			popErr = PopulateByName(newObj, rows, meta)
 		}
 
 		if popErr != nil {
-			err = popErr
-			return
+			return popErr
 		}
 		newObjValue := reflectValue(newObj)
 		collectionValue.Set(reflect.Append(collectionValue, newObjValue))
 		didSetRows = true
+		return nil
+	})
+	if err != nil {
+		return
 	}
 
 	if !didSetRows {
 		collectionValue.Set(reflect.MakeSlice(sliceType, 0, 0))
 	}
+
+	if q.keyset != nil && q.keyset.limit > 0 && collectionValue.Len() >= q.keyset.limit {
+		q.nextCursor, err = encodeCursor(collectionValue.Index(collectionValue.Len()-1).Interface(), q.keyset.columns)
+	}
 	return
 }
 
@@ -259,26 +393,123 @@ func (q *Query) OutMany(collection interface{}) (err error) {
 func (q *Query) Each(consumer RowsConsumer) (err error) {
 	defer func() { err = q.panicHandler(recover(), err) }()
 
-	q.stmt, q.rows, q.err = q.Execute()
-	if q.err != nil {
-		return q.err
+	err = q.forEachRow(consumer)
+	return
+}
+
+// EachObject streams the results of the query as objects of the same type as the prototype, one row at a time.
+// Each row is read into a new pointer to the prototype type with `Populate(...)` if the type is `Populatable`,
+// and `PopulateByName(...)` otherwise. Returning an error from the consumer stops the iteration, and
+// the rows and statement are closed on return either way.
+func (q *Query) EachObject(prototype interface{}, consumer ObjectConsumer) error {
+	objectType := reflectType(prototype)
+	meta := getCachedColumnCollectionFromType(newColumnCacheKey(objectType), objectType)
+	if err := meta.Err(); err != nil {
+		return err
 	}
+	populatable := isPopulatable(makeNew(objectType))
 
-	rowsErr := q.rows.Err()
-	if rowsErr != nil {
-		err = exception.Wrap(rowsErr)
+	return q.Each(func(rows *sql.Rows) error {
+		newObj := makeNew(objectType)
+
+		var popErr error
+		if populatable {
+			popErr = asPopulatable(newObj).Populate(rows)
+		} else {
+			popErr = PopulateByName(newObj, rows, meta)
+		}
+		if popErr != nil {
+			return popErr
+		}
+		return consumer(newObj)
+	})
+}
+
+// OutJoined writes the rows of a joined query to a slice per database mapped type in the join.
+// Each target reads the columns selected with its prefix, and rows are deduplicated per target by primary key,
+// so the left side of a one to many join is only read once. Targets with no non-null columns in a row are skipped.
+//
+//	var users []User
+//	var orders []Order
+//	err := spiffy.Default().Query(query).OutJoined(spiffy.Prefixed(&users, "u_"), spiffy.Prefixed(&orders, "o_"))
+func (q *Query) OutJoined(targets ...JoinTarget) (err error) {
+	defer func() { err = q.panicHandler(recover(), err) }()
+
+	collections := make([]reflect.Value, len(targets))
+	types := make([]reflect.Type, len(targets))
+	for x, target := range targets {
+		if reflectType(target.Target).Kind() != reflect.Slice {
+			err = exception.New("destination collection is not a slice")
+			return
+		}
+		collections[x] = reflectValue(target.Target)
+		types[x] = reflectSliceType(target.Target)
+	}
+
+	mapping, err := newJoinMapping(targets, types)
+	if err != nil {
 		return
 	}
 
-	for q.rows.Next() {
-		err = consumer(q.rows)
-		if err != nil {
-			return err
+	seen := make([]map[string]bool, len(targets))
+	for x := range seen {
+		seen[x] = map[string]bool{}
+	}
+
+	err = q.forEachRow(func(rows *sql.Rows) error {
+		objects, popErr := mapping.populate(rows)
+		if popErr != nil {
+			return popErr
+		}
+		for x, object := range objects {
+			if object == nil {
+				continue
+			}
+			if key, hasKey := mapping.key(x, object); hasKey {
+				if seen[x][key] {
+					continue
+				}
+				seen[x][key] = true
+			}
+			collections[x].Set(reflect.Append(collections[x], reflectValue(object)))
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	for _, collection := range collections {
+		if collection.IsNil() {
+			collection.Set(reflect.MakeSlice(collection.Type(), 0, 0))
 		}
 	}
 	return
 }
 
+// EachJoined streams the rows of a joined query as a new object per join target, one row at a time.
+// Each target reads the columns selected with its prefix; targets with no non-null columns in a row are nil.
+// Rows are not deduplicated.
+func (q *Query) EachJoined(consumer JoinedConsumer, targets ...JoinTarget) error {
+	types := make([]reflect.Type, len(targets))
+	for x, target := range targets {
+		types[x] = reflectType(target.Target)
+	}
+
+	mapping, err := newJoinMapping(targets, types)
+	if err != nil {
+		return err
+	}
+
+	return q.Each(func(rows *sql.Rows) error {
+		objects, popErr := mapping.populate(rows)
+		if popErr != nil {
+			return popErr
+		}
+		return consumer(objects)
+	})
+}
+
 // --------------------------------------------------------------------------------
 // helpers
 // --------------------------------------------------------------------------------
@@ -293,10 +524,168 @@ func (q *Query) panicHandler(r interface{}, err error) error {
 		err = exception.Nest(err, closeErr)
 	}
 
-	q.db.conn.fireEvent(EventFlagQuery, q.statement, time.Since(q.start), err, q.statementLabel)
+	statement, _ := q.boundStatement()
+	err = contextCanceledError(q.ctx, err, statement)
+
+	q.db.conn.fireEvent(EventFlagQuery, statement, time.Since(q.start), err, q.statementLabel)
 	return err
 }
 
+// boundStatement returns the statement and its arguments with the page, if one is set, bound after the other arguments.
+func (q *Query) boundStatement() (string, []interface{}) {
+	if q.page != nil {
+		args := append(append([]interface{}{}, q.args...), q.page.limit, q.page.offset)
+		statement := strings.TrimRight(strings.TrimSpace(q.statement), ";") +
+			" LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+		return statement, args
+	}
+	if q.keyset != nil {
+		return q.keyset.bind(q.statement, q.args)
+	}
+	return q.statement, q.args
+}
+
+// bind wraps a statement in a subquery that selects the page after the keyset's cursor, returning it with its arguments.
+func (kp *keysetPage) bind(statement string, args []interface{}) (string, []interface{}) {
+	args = append([]interface{}{}, args...)
+	columns := make([]string, len(kp.columns))
+	for x, col := range kp.columns {
+		columns[x] = col.ColumnName
+	}
+
+	buffer := bytes.NewBufferString("SELECT * FROM (")
+	buffer.WriteString(strings.TrimRight(strings.TrimSpace(statement), ";"))
+	buffer.WriteString(") AS keyset_page")
+
+	if len(kp.after) > 0 {
+		tokens := make([]string, len(kp.after))
+		for x, value := range kp.after {
+			args = append(args, value)
+			tokens[x] = "$" + strconv.Itoa(len(args))
+		}
+		buffer.WriteString(" WHERE (" + CSV(columns) + ") > (" + CSV(tokens) + ")")
+	}
+
+	args = append(args, kp.limit)
+	buffer.WriteString(" ORDER BY " + CSV(columns) + " LIMIT $" + strconv.Itoa(len(args)))
+	return buffer.String(), args
+}
+
+// forEachRow executes the query and calls the consumer for each row, reading through a server-side cursor if one is set.
+func (q *Query) forEachRow(consumer RowsConsumer) (err error) {
+	if q.cursorBatchSize > 0 {
+		return q.forEachRowWithCursor(consumer)
+	}
+
+	q.stmt, q.rows, q.err = q.Execute()
+	if q.err != nil {
+		return q.err
+	}
+
+	for q.rows.Next() {
+		err = consumer(q.rows)
+		if err != nil {
+			return
+		}
+	}
+
+	if rowsErr := q.rows.Err(); rowsErr != nil {
+		err = exception.Wrap(rowsErr)
+	}
+	return
+}
+
+// forEachRowWithCursor declares a server-side cursor for the query and fetches from it in batches.
+func (q *Query) forEachRowWithCursor(consumer RowsConsumer) (err error) {
+	if q.err != nil {
+		return q.err
+	}
+
+	ctx := q.Context()
+	tx := q.db.tx
+	if tx == nil {
+		tx, err = q.db.conn.BeginContext(ctx)
+		if err != nil {
+			return exception.Wrap(err)
+		}
+		defer tx.Rollback()
+	}
+
+	cursorName := "spiffy_cursor_" + strconv.FormatUint(atomic.AddUint64(&cursorCount, 1), 10)
+	statement, args := q.boundStatement()
+	if _, err = tx.ExecContext(ctx, "DECLARE "+cursorName+" NO SCROLL CURSOR FOR "+statement, args...); err != nil {
+		return exception.Wrap(err)
+	}
+	defer func() {
+		if q.rows != nil {
+			q.rows.Close()
+			q.rows = nil
+		}
+		if _, closeErr := tx.ExecContext(ctx, "CLOSE "+cursorName); closeErr != nil && err == nil {
+			err = exception.Wrap(closeErr)
+		}
+	}()
+
+	fetch := "FETCH FORWARD " + strconv.Itoa(q.cursorBatchSize) + " FROM " + cursorName
+	for {
+		q.rows, err = tx.QueryContext(ctx, fetch)
+		if err != nil {
+			return exception.Wrap(err)
+		}
+
+		var fetched int
+		for q.rows.Next() {
+			fetched++
+			if err = consumer(q.rows); err != nil {
+				return
+			}
+		}
+		if rowsErr := q.rows.Err(); rowsErr != nil {
+			return exception.Wrap(rowsErr)
+		}
+		if err = q.rows.Close(); err != nil {
+			return exception.Wrap(err)
+		}
+		q.rows = nil
+
+		if fetched < q.cursorBatchSize {
+			return
+		}
+	}
+}
+
+// encodeCursor returns an opaque cursor for the ordering column values of an object.
+func encodeCursor(object interface{}, columns []*Column) (string, error) {
+	value := reflectValue(object)
+	values := make([]interface{}, len(columns))
+	for x, col := range columns {
+		if field := col.fieldValue(value, false); field.IsValid() {
+			values[x] = field.Interface()
+		}
+	}
+	contents, err := json.Marshal(values)
+	if err != nil {
+		return "", exception.Wrap(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(contents), nil
+}
+
+// decodeCursor returns the ordering column values from an opaque cursor.
+func decodeCursor(cursor string) ([]interface{}, error) {
+	contents, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, exception.New("invalid keyset cursor.")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+
+	var values []interface{}
+	if err = decoder.Decode(&values); err != nil {
+		return nil, exception.New("invalid keyset cursor.")
+	}
+	return values, nil
+}
+
 func (q *Query) shouldCacheStatement() bool {
 	return q.db.conn.useStatementCache && len(q.statementLabel) > 0
 }
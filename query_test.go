@@ -2,6 +2,7 @@ package spiffy
 
 import (
 	"database/sql"
+	"fmt"
 	"sync"
 	"testing"
 
@@ -46,6 +47,46 @@ func TestQueryEach(t *testing.T) {
 	a.NotEmpty(all)
 }
 
+func TestQueryEachObject(t *testing.T) {
+	a := assert.New(t)
+	tx, err := Default().Begin()
+	a.Nil(err)
+	defer tx.Rollback()
+
+	a.Nil(seedObjects(10, tx))
+
+	var all []benchObj
+	err = Default().QueryInTx("select * from bench_object", tx).EachObject(benchObj{}, func(obj interface{}) error {
+		typed, isTyped := obj.(*benchObj)
+		a.True(isTyped)
+		all = append(all, *typed)
+		return nil
+	})
+	a.Nil(err)
+	a.True(len(all) >= 10)
+
+	stopErr := fmt.Errorf("stop")
+	var seen int
+	err = Default().QueryInTx("select * from bench_object", tx).EachObject(&benchObj{}, func(obj interface{}) error {
+		seen++
+		return stopErr
+	})
+	a.NotNil(err)
+	a.Equal(1, seen)
+
+	var ids []int
+	err = Default().QueryInTx("select * from bench_object", tx).EachObject(idOnlyObj{}, func(obj interface{}) error {
+		ids = append(ids, obj.(*idOnlyObj).ID)
+		return nil
+	})
+	a.Nil(err)
+	a.Len(ids, len(all))
+}
+
+type idOnlyObj struct {
+	ID int `db:"id"`
+}
+
 func TestQueryAny(t *testing.T) {
 	a := assert.New(t)
 	tx, err := Default().Begin()
@@ -202,3 +243,229 @@ func TestMultipleQueriesPerTransactionWithFailure(t *testing.T) {
 	a.NotNil(err)
 	a.False(hasRows)
 }
+
+func TestQueryPage(t *testing.T) {
+	a := assert.New(t)
+
+	q := &Query{statement: "select * from bench_object where pending = $1 order by id;", args: []interface{}{true}}
+	q.Page(5, 0).Page(10, 20)
+	statement, args := q.boundStatement()
+	a.Equal("select * from bench_object where pending = $1 order by id LIMIT $2 OFFSET $3", statement)
+	a.Equal([]interface{}{true, 10, 20}, args)
+	a.Len(q.args, 1, "the page is not added to the query's own arguments")
+}
+
+func TestQueryKeysetStatement(t *testing.T) {
+	a := assert.New(t)
+
+	q := &Query{statement: "select * from bench_object where pending = $1", args: []interface{}{true}}
+	q.Keyset(benchObj{}, "", 5)
+	a.Nil(q.err)
+	statement, args := q.boundStatement()
+	a.Equal("SELECT * FROM (select * from bench_object where pending = $1) AS keyset_page ORDER BY id LIMIT $2", statement)
+	a.Equal([]interface{}{true, 5}, args)
+	a.Len(q.args, 1, "the keyset is not added to the query's own arguments")
+
+	cursor, err := encodeCursor(benchObj{ID: 3, Name: "foo"}, []*Column{Columns(benchObj{}).Lookup()["id"], Columns(benchObj{}).Lookup()["name"]})
+	a.Nil(err)
+
+	q = &Query{statement: "select * from bench_object", args: []interface{}{}}
+	q.Keyset(benchObj{}, cursor, 5, "id", "name")
+	a.Nil(q.err)
+	statement, args = q.boundStatement()
+	a.Equal("SELECT * FROM (select * from bench_object) AS keyset_page WHERE (id,name) > ($1,$2) ORDER BY id,name LIMIT $3", statement)
+	a.Equal("3", fmt.Sprintf("%v", args[0]))
+	a.Equal("foo", args[1])
+
+	// calling it again replaces the keyset rather than wrapping the statement twice.
+	q.Keyset(benchObj{}, "", 10)
+	statement, args = q.boundStatement()
+	a.Equal("SELECT * FROM (select * from bench_object) AS keyset_page ORDER BY id LIMIT $1", statement)
+	a.Equal([]interface{}{10}, args)
+
+	// the last of `Keyset` and `Page` to be called is used.
+	q.Page(10, 20)
+	statement, args = q.boundStatement()
+	a.Equal("select * from bench_object LIMIT $1 OFFSET $2", statement)
+	a.Equal([]interface{}{10, 20}, args)
+
+	q.Keyset(benchObj{}, "", 5)
+	statement, args = q.boundStatement()
+	a.Equal("SELECT * FROM (select * from bench_object) AS keyset_page ORDER BY id LIMIT $1", statement)
+	a.Equal([]interface{}{5}, args)
+
+	q = &Query{statement: "select * from bench_object"}
+	q.Keyset(benchObj{}, "", 5, "not_a_column")
+	a.NotNil(q.err)
+
+	q = &Query{statement: "select * from bench_object"}
+	q.Keyset(benchObj{}, "not a cursor!", 5)
+	a.NotNil(q.err)
+
+	q = &Query{statement: "select * from bench_object"}
+	q.Keyset(benchObj{}, cursor, 5)
+	a.NotNil(q.err, "the cursor has two values but the default ordering has one column")
+}
+
+func TestQueryKeysetCursorEmbedded(t *testing.T) {
+	a := assert.New(t)
+
+	// a column of a nil embedded struct encodes as null rather than panicking.
+	cursor, err := encodeCursor(embeddedObj{ID: 1}, []*Column{Columns(embeddedObj{}).Lookup()["id"], Columns(embeddedObj{}).Lookup()["owner_name"]})
+	a.Nil(err)
+	values, err := decodeCursor(cursor)
+	a.Nil(err)
+	a.Len(values, 2)
+	a.Nil(values[1])
+}
+
+func TestQueryKeyset(t *testing.T) {
+	a := assert.New(t)
+	tx, err := Default().Begin()
+	a.Nil(err)
+	defer tx.Rollback()
+
+	a.Nil(seedObjects(10, tx))
+
+	var all []benchObj
+	a.Nil(Default().GetAllInTx(&all, tx))
+
+	var paged []benchObj
+	var cursor string
+	for pages := 0; pages < len(all); pages++ {
+		var page []benchObj
+		query := Default().QueryInTx("select * from bench_object", tx).Keyset(benchObj{}, cursor, 3)
+		a.Nil(query.OutMany(&page))
+		paged = append(paged, page...)
+		cursor = query.NextCursor()
+		if len(cursor) == 0 {
+			break
+		}
+	}
+	a.Len(paged, len(all))
+	for x := 1; x < len(paged); x++ {
+		a.True(paged[x-1].ID < paged[x].ID)
+	}
+}
+
+func TestQueryWithCursor(t *testing.T) {
+	a := assert.New(t)
+	tx, err := Default().Begin()
+	a.Nil(err)
+	defer tx.Rollback()
+
+	a.Nil(seedObjects(10, tx))
+
+	var all []benchObj
+	a.Nil(Default().GetAllInTx(&all, tx))
+
+	var batched []benchObj
+	a.Nil(Default().QueryInTx("select * from bench_object;", tx).WithCursor(3).OutMany(&batched))
+	a.Len(batched, len(all))
+
+	var count int
+	a.Nil(Default().QueryInTx("select * from bench_object", tx).WithCursor(4).EachObject(benchObj{}, func(obj interface{}) error {
+		count++
+		return nil
+	}))
+	a.Equal(len(all), count)
+
+	count = 0
+	err = Default().QueryInTx("select * from bench_object", tx).WithCursor(2).Each(func(r *sql.Rows) error {
+		count++
+		if count == 3 {
+			return fmt.Errorf("stop")
+		}
+		return nil
+	})
+	a.NotNil(err)
+	a.Equal(3, count)
+
+	exists, err := Default().QueryInTx("select 1 from bench_object", tx).Any()
+	a.Nil(err, "the transaction should still be usable after stopping early")
+	a.True(exists)
+}
+
+func TestQueryWithCursorOutsideTransaction(t *testing.T) {
+	a := assert.New(t)
+
+	var all []benchObj
+	a.Nil(Default().GetAll(&all))
+
+	var batched []benchObj
+	a.Nil(Default().Query("select * from bench_object").WithCursor(5).OutMany(&batched))
+	a.Len(batched, len(all))
+
+	a.NotNil(Default().Query("select * from bench_object").WithCursor(0).OutMany(&batched))
+}
+
+func seedJoinedObjects(tx *sql.Tx) ([]relUser, error) {
+	if err := createRelationTables(tx); err != nil {
+		return nil, err
+	}
+	users := []relUser{{Name: "foo"}, {Name: "bar"}}
+	if err := Default().CreateManyInTx(users, tx); err != nil {
+		return nil, err
+	}
+	orders := []relOrder{{UserID: &users[0].ID, Amount: 1}, {UserID: &users[0].ID, Amount: 2}}
+	return users, Default().CreateManyInTx(orders, tx)
+}
+
+func joinedQuery(users []relUser) string {
+	return fmt.Sprintf("select %s, %s from rel_user u left join rel_order o on o.user_id = u.id where u.id in (%d, %d) order by u.id, o.id",
+		Columns(relUser{}).CopyWithColumnPrefix("u_").ColumnNamesCSVFromAlias("u"),
+		Columns(relOrder{}).CopyWithColumnPrefix("o_").ColumnNamesCSVFromAlias("o"),
+		users[0].ID, users[1].ID,
+	)
+}
+
+func TestQueryOutJoined(t *testing.T) {
+	a := assert.New(t)
+	tx, err := Default().Begin()
+	a.Nil(err)
+	defer tx.Rollback()
+
+	users, err := seedJoinedObjects(tx)
+	a.Nil(err)
+
+	var readUsers []relUser
+	var readOrders []relOrder
+	err = Default().QueryInTx(joinedQuery(users), tx).OutJoined(Prefixed(&readUsers, "u_"), Prefixed(&readOrders, "o_"))
+	a.Nil(err)
+	a.Len(readUsers, 2)
+	a.Equal("foo", readUsers[0].Name)
+	a.Equal("bar", readUsers[1].Name)
+	a.Len(readOrders, 2)
+	a.Equal(users[0].ID, *readOrders[0].UserID)
+	a.Equal(2, readOrders[1].Amount)
+
+	var notSlice relUser
+	a.NotNil(Default().QueryInTx(joinedQuery(users), tx).OutJoined(Prefixed(&notSlice, "u_")))
+}
+
+func TestQueryEachJoined(t *testing.T) {
+	a := assert.New(t)
+	tx, err := Default().Begin()
+	a.Nil(err)
+	defer tx.Rollback()
+
+	users, err := seedJoinedObjects(tx)
+	a.Nil(err)
+
+	var rows, unmatched int
+	err = Default().QueryInTx(joinedQuery(users), tx).EachJoined(func(objects []interface{}) error {
+		rows++
+		a.Len(objects, 2)
+		user := objects[0].(*relUser)
+		if objects[1] == nil {
+			unmatched++
+			a.Equal("bar", user.Name)
+			return nil
+		}
+		a.Equal(user.ID, *objects[1].(*relOrder).UserID)
+		return nil
+	}, Prefixed(relUser{}, "u_"), Prefixed(relOrder{}, "o_"))
+	a.Nil(err)
+	a.Equal(3, rows)
+	a.Equal(1, unmatched)
+}
@@ -0,0 +1,294 @@
+package spiffy
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	exception "github.com/blendlabs/go-exception"
+	"github.com/lib/pq"
+)
+
+const (
+	// RelationHasMany is a relation where the related table has a foreign key to the object's primary key.
+	// It is declared on a slice field, e.g. `rel:"has_many,fk=user_id"`.
+	RelationHasMany = "has_many"
+	// RelationBelongsTo is a relation where the object has a foreign key to the related table's primary key.
+	// It is declared on a struct or struct reference field, e.g. `rel:"belongs_to,fk=user_id"`.
+	RelationBelongsTo = "belongs_to"
+)
+
+// Relation is a field of a database mapped struct that holds related objects, declared with a `rel` tag.
+// The field itself isn't a column, so it should also be tagged `db:"-"`.
+type Relation struct {
+	FieldName  string
+	FieldType  reflect.Type
+	Kind       string
+	ForeignKey string
+
+	column Column
+}
+
+// RelatedType returns the database mapped type of the related objects.
+func (r Relation) RelatedType() reflect.Type {
+	t := r.FieldType
+	if r.Kind == RelationHasMany {
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// NewRelationFromFieldTag reads the `rel` tag of a struct field, e.g. `rel:"has_many,fk=user_id"`.
+func NewRelationFromFieldTag(field reflect.StructField) (*Relation, error) {
+	tag := field.Tag.Get("rel")
+	if len(tag) == 0 {
+		return nil, exception.Newf("field `%s` does not have a `rel` tag.", field.Name)
+	}
+
+	rel := Relation{
+		FieldName: field.Name,
+		FieldType: field.Type,
+		column:    Column{FieldName: field.Name, FieldType: field.Type, indexPath: field.Index},
+	}
+
+	pieces := strings.Split(tag, ",")
+	rel.Kind = strings.ToLower(strings.TrimSpace(pieces[0]))
+	for _, piece := range pieces[1:] {
+		separator := strings.Index(piece, "=")
+		if separator < 0 {
+			continue
+		}
+		if strings.ToLower(strings.TrimSpace(piece[:separator])) == "fk" {
+			rel.ForeignKey = strings.TrimSpace(piece[separator+1:])
+		}
+	}
+
+	if len(rel.ForeignKey) == 0 {
+		return nil, exception.Newf("relation `%s` does not set a foreign key, e.g. `fk=user_id`.", field.Name)
+	}
+
+	elemType := field.Type
+	switch rel.Kind {
+	case RelationHasMany:
+		if elemType.Kind() != reflect.Slice {
+			return nil, exception.Newf("has_many relation `%s` must be a slice.", field.Name)
+		}
+		elemType = elemType.Elem()
+	case RelationBelongsTo:
+	default:
+		return nil, exception.Newf("relation `%s` has an unknown kind `%s`.", field.Name, rel.Kind)
+	}
+
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, exception.Newf("relation `%s` must hold structs.", field.Name)
+	}
+	if _, err := makeNewDatabaseMapped(elemType); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// relationForField returns the relation declared on a named field of a struct type.
+func relationForField(t reflect.Type, fieldName string) (*Relation, error) {
+	field, hasField := t.FieldByName(fieldName)
+	if !hasField {
+		return nil, exception.Newf("`%s` does not have a field `%s`.", t.Name(), fieldName)
+	}
+	return NewRelationFromFieldTag(field)
+}
+
+// singlePrimaryKey returns the primary key of a type, which relations require to be a single column.
+func singlePrimaryKey(tableName string, cols *ColumnCollection) (*Column, error) {
+	pks := cols.PrimaryKeys()
+	if pks.Len() != 1 {
+		return nil, exception.Newf("relations to `%s` require it to have exactly one primary key.", tableName)
+	}
+	return pks.FirstOrDefault(), nil
+}
+
+// relationKey returns a comparable key and the raw value of a key column, following pointers and `driver.Valuer`s.
+// Null keys return false.
+func relationKey(value interface{}) (string, interface{}, bool) {
+	if valuer, isValuer := value.(driver.Valuer); isValuer {
+		v, err := valuer.Value()
+		if err != nil {
+			return "", nil, false
+		}
+		value = v
+	}
+
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", nil, false
+	}
+	return fmt.Sprintf("%v", v.Interface()), v.Interface(), true
+}
+
+// relationValue returns the value of a key column of an object as it is written, so keys with a type converter
+// are compared and bound by their encoded values.
+func relationValue(col *Column, object DatabaseMapped) interface{} {
+	values, _ := newColumnCollectionFromColumns([]Column{*col}).columnValues(object)
+	return values[0]
+}
+
+// preloadElements returns references to the elements of a slice of structs or struct pointers.
+func preloadElements(collectionValue reflect.Value) ([]DatabaseMapped, error) {
+	elements := make([]DatabaseMapped, 0, collectionValue.Len())
+	for x := 0; x < collectionValue.Len(); x++ {
+		element := collectionValue.Index(x)
+		if element.Kind() == reflect.Ptr {
+			if element.IsNil() {
+				continue
+			}
+		} else {
+			element = element.Addr()
+		}
+		object, isDatabaseMapped := element.Interface().(DatabaseMapped)
+		if !isDatabaseMapped {
+			return nil, exception.Newf("`%s` does not implement DatabaseMapped.", element.Type().Elem().Name())
+		}
+		elements = append(elements, object)
+	}
+	return elements, nil
+}
+
+// preloadHasMany loads the related objects of a has_many relation with one query and assigns them to each element.
+func (i *Invocation) preloadHasMany(elements []DatabaseMapped, parentTableName string, parentCols *ColumnCollection, rel *Relation) error {
+	pk, err := singlePrimaryKey(parentTableName, parentCols)
+	if err != nil {
+		return err
+	}
+
+	relatedType := rel.RelatedType()
+	relatedTableName, _ := TableName(relatedType)
+	relatedCols := getCachedColumnCollectionFromType(newColumnCacheKey(relatedType), relatedType)
+	if err = relatedCols.Err(); err != nil {
+		return err
+	}
+	fk, hasForeignKey := relatedCols.Lookup()[rel.ForeignKey]
+	if !hasForeignKey {
+		return exception.Newf("relation `%s` foreign key `%s` is not a column of `%s`.", rel.FieldName, rel.ForeignKey, relatedTableName)
+	}
+
+	keys := relationKeys(elements, pk)
+	related, err := i.preloadRelated(relatedType, relatedTableName, fk, keys)
+	if err != nil {
+		return err
+	}
+
+	byKey := map[string][]reflect.Value{}
+	for x := 0; x < related.Len(); x++ {
+		element := related.Index(x)
+		if key, _, hasKey := relationKey(relationValue(fk, element.Addr().Interface().(DatabaseMapped))); hasKey {
+			byKey[key] = append(byKey[key], element)
+		}
+	}
+
+	relatedIsPtr := rel.FieldType.Elem().Kind() == reflect.Ptr
+	for _, element := range elements {
+		key, _, _ := relationKey(relationValue(pk, element))
+		matched := byKey[key]
+		children := reflect.MakeSlice(rel.FieldType, 0, len(matched))
+		for _, child := range matched {
+			if relatedIsPtr {
+				children = reflect.Append(children, child.Addr())
+			} else {
+				children = reflect.Append(children, child)
+			}
+		}
+		if err = rel.column.SetValue(element, children.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// preloadBelongsTo loads the related objects of a belongs_to relation with one query and assigns them to each element.
+// Elements with a null foreign key, or whose related row isn't found, are left as they are.
+func (i *Invocation) preloadBelongsTo(elements []DatabaseMapped, parentTableName string, parentCols *ColumnCollection, rel *Relation) error {
+	fk, hasForeignKey := parentCols.Lookup()[rel.ForeignKey]
+	if !hasForeignKey {
+		return exception.Newf("relation `%s` foreign key `%s` is not a column of `%s`.", rel.FieldName, rel.ForeignKey, parentTableName)
+	}
+
+	relatedType := rel.RelatedType()
+	relatedTableName, _ := TableName(relatedType)
+	relatedCols := getCachedColumnCollectionFromType(newColumnCacheKey(relatedType), relatedType)
+	if err := relatedCols.Err(); err != nil {
+		return err
+	}
+	pk, err := singlePrimaryKey(relatedTableName, relatedCols)
+	if err != nil {
+		return err
+	}
+
+	keys := relationKeys(elements, fk)
+	related, err := i.preloadRelated(relatedType, relatedTableName, pk, keys)
+	if err != nil {
+		return err
+	}
+
+	byKey := map[string]reflect.Value{}
+	for x := 0; x < related.Len(); x++ {
+		element := related.Index(x)
+		if key, _, hasKey := relationKey(relationValue(pk, element.Addr().Interface().(DatabaseMapped))); hasKey {
+			byKey[key] = element.Addr()
+		}
+	}
+
+	for _, element := range elements {
+		key, _, hasKey := relationKey(relationValue(fk, element))
+		if !hasKey {
+			continue
+		}
+		if parent, hasParent := byKey[key]; hasParent {
+			if err = rel.column.SetValue(element, parent.Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// relationKeys returns the distinct, non-null values of a key column across a set of elements.
+func relationKeys(elements []DatabaseMapped, col *Column) []interface{} {
+	var keys []interface{}
+	seen := map[string]bool{}
+	for _, element := range elements {
+		key, value, hasKey := relationKey(relationValue(col, element))
+		if !hasKey || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, value)
+	}
+	return keys
+}
+
+// preloadRelated reads the rows of a related table whose key column is one of a set of values.
+func (i *Invocation) preloadRelated(relatedType reflect.Type, relatedTableName string, key *Column, keys []interface{}) (reflect.Value, error) {
+	related := reflect.New(reflect.SliceOf(relatedType))
+	if len(keys) == 0 {
+		return related.Elem(), nil
+	}
+
+	// a fresh invocation keeps the statement label (and any cached statement) of this one from being reused.
+	invocation := &Invocation{db: i.db, ctx: i.ctx, fireEvents: i.fireEvents, unscoped: i.unscoped}
+	if err := invocation.getMany(related.Interface(), key.ColumnName+" = ANY($1)", pq.Array(keys)); err != nil {
+		return related.Elem(), err
+	}
+	return related.Elem(), nil
+}
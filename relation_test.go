@@ -0,0 +1,130 @@
+package spiffy
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+type relUser struct {
+	ID     int         `db:"id,pk,serial"`
+	Name   string      `db:"name"`
+	Orders []*relOrder `db:"-" rel:"has_many,fk=user_id"`
+}
+
+func (ru relUser) TableName() string {
+	return "rel_user"
+}
+
+type relOrder struct {
+	ID     int      `db:"id,pk,serial"`
+	UserID *int     `db:"user_id"`
+	Amount int      `db:"amount"`
+	User   *relUser `db:"-" rel:"belongs_to,fk=user_id"`
+}
+
+func (ro relOrder) TableName() string {
+	return "rel_order"
+}
+
+type badRelObj struct {
+	ID      int        `db:"id,pk"`
+	NoKey   []relOrder `db:"-" rel:"has_many"`
+	NotMany relOrder   `db:"-" rel:"has_many,fk=user_id"`
+	Unknown relOrder   `db:"-" rel:"has_one,fk=user_id"`
+	Strings []string   `db:"-" rel:"has_many,fk=user_id"`
+}
+
+func (bro badRelObj) TableName() string {
+	return "bad_rel_object"
+}
+
+func TestNewRelationFromFieldTag(t *testing.T) {
+	assert := assert.New(t)
+
+	rel, err := relationForField(reflect.TypeOf(relUser{}), "Orders")
+	assert.Nil(err)
+	assert.Equal(RelationHasMany, rel.Kind)
+	assert.Equal("user_id", rel.ForeignKey)
+	assert.Equal(reflect.TypeOf(relOrder{}), rel.RelatedType())
+
+	rel, err = relationForField(reflect.TypeOf(relOrder{}), "User")
+	assert.Nil(err)
+	assert.Equal(RelationBelongsTo, rel.Kind)
+	assert.Equal(reflect.TypeOf(relUser{}), rel.RelatedType())
+
+	assert.False(Columns(relUser{}).HasColumn("orders"))
+
+	badType := reflect.TypeOf(badRelObj{})
+	for _, name := range []string{"ID", "NoKey", "NotMany", "Unknown", "Strings", "Missing"} {
+		_, err = relationForField(badType, name)
+		assert.NotNil(err, name)
+	}
+}
+
+func createRelationTables(tx *sql.Tx) error {
+	err := Default().ExecInTx(`CREATE TABLE IF NOT EXISTS rel_user (id serial not null primary key, name varchar(255));`, tx)
+	if err != nil {
+		return err
+	}
+	return Default().ExecInTx(`CREATE TABLE IF NOT EXISTS rel_order (id serial not null primary key, user_id int references rel_user(id), amount int);`, tx)
+}
+
+func TestInvocationPreload(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(createRelationTables(tx))
+
+	users := []relUser{{Name: "foo"}, {Name: "bar"}, {Name: "baz"}}
+	for x := range users {
+		assert.Nil(Default().CreateInTx(&users[x], tx))
+	}
+	orders := []relOrder{
+		{UserID: &users[0].ID, Amount: 1},
+		{UserID: &users[0].ID, Amount: 2},
+		{UserID: &users[1].ID, Amount: 3},
+		{Amount: 4},
+	}
+	assert.Nil(Default().CreateManyInTx(orders, tx))
+
+	var readUsers []relUser
+	assert.Nil(Default().GetAllInTx(&readUsers, tx))
+	assert.Nil(Default().PreloadInTx(&readUsers, tx, "Orders"))
+	assert.Len(readUsers, 3)
+	for _, user := range readUsers {
+		switch user.ID {
+		case users[0].ID:
+			assert.Len(user.Orders, 2)
+		case users[1].ID:
+			assert.Len(user.Orders, 1)
+			assert.Equal(3, user.Orders[0].Amount)
+		default:
+			assert.NotNil(user.Orders)
+			assert.Empty(user.Orders)
+		}
+	}
+
+	var allOrders []relOrder
+	assert.Nil(Default().GetAllInTx(&allOrders, tx))
+	readOrders := make([]*relOrder, len(allOrders))
+	for x := range allOrders {
+		readOrders[x] = &allOrders[x]
+	}
+	assert.Nil(Default().DB().InTx(tx).Invoke().Preload(readOrders, "User"))
+	assert.Len(readOrders, 4)
+	for _, order := range readOrders {
+		if order.UserID == nil {
+			assert.Nil(order.User)
+			continue
+		}
+		assert.NotNil(order.User)
+		assert.Equal(*order.UserID, order.User.ID)
+	}
+
+	assert.NotNil(Default().PreloadInTx(readOrders, tx, "Amount"))
+}
@@ -0,0 +1,165 @@
+package spiffy
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Select returns a new select statement builder for a given set of columns.
+//
+//	statement, args := spiffy.Select("id", "name").From("users").
+//		Where("created_utc > $1", since).
+//		WhereIf(len(name) > 0, "name = $1", name).
+//		OrderBy("id").Limit(10).ToSQL()
+//
+// Each fragment numbers its own parameters from `$1`; they are renumbered in the order the fragments are added.
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{columns: columns}
+}
+
+// SelectBuilder builds a select statement and its ordered arguments.
+type SelectBuilder struct {
+	columns []string
+	from    string
+	joins   []string
+	where   []string
+	groupBy []string
+	orderBy []string
+	limit   *int
+	offset  *int
+	args    []interface{}
+}
+
+// Columns adds the columns of a database mapped type, qualified with a table alias.
+func (sb *SelectBuilder) Columns(object DatabaseMapped, tableAlias string) *SelectBuilder {
+	sb.columns = append(sb.columns, Columns(object).ColumnNamesCSVFromAlias(tableAlias))
+	return sb
+}
+
+// From sets the table (and optional alias, i.e. `users u`) to select from.
+func (sb *SelectBuilder) From(table string) *SelectBuilder {
+	sb.from = table
+	return sb
+}
+
+// Join adds a join clause, i.e. `JOIN orders o ON o.user_id = u.id`.
+func (sb *SelectBuilder) Join(clause string, args ...interface{}) *SelectBuilder {
+	sb.joins = append(sb.joins, sb.addArgs(clause, args))
+	return sb
+}
+
+// Where adds a predicate; multiple predicates are joined with `AND`.
+func (sb *SelectBuilder) Where(predicate string, args ...interface{}) *SelectBuilder {
+	sb.where = append(sb.where, "("+sb.addArgs(predicate, args)+")")
+	return sb
+}
+
+// WhereIf adds a predicate only if a condition is true.
+func (sb *SelectBuilder) WhereIf(condition bool, predicate string, args ...interface{}) *SelectBuilder {
+	if condition {
+		return sb.Where(predicate, args...)
+	}
+	return sb
+}
+
+// GroupBy sets the group by columns.
+func (sb *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
+	sb.groupBy = append(sb.groupBy, columns...)
+	return sb
+}
+
+// OrderBy sets the order by expressions, i.e. `OrderBy("created_utc desc", "id")`.
+func (sb *SelectBuilder) OrderBy(expressions ...string) *SelectBuilder {
+	sb.orderBy = append(sb.orderBy, expressions...)
+	return sb
+}
+
+// Limit sets the maximum number of rows returned; calling it again replaces the limit.
+func (sb *SelectBuilder) Limit(limit int) *SelectBuilder {
+	sb.limit = &limit
+	return sb
+}
+
+// Offset sets the number of rows to skip; calling it again replaces the offset.
+func (sb *SelectBuilder) Offset(offset int) *SelectBuilder {
+	sb.offset = &offset
+	return sb
+}
+
+// Args returns the ordered arguments for the statement.
+func (sb *SelectBuilder) Args() []interface{} {
+	_, args := sb.ToSQL()
+	return args
+}
+
+// String returns the sql statement.
+func (sb *SelectBuilder) String() string {
+	statement, _ := sb.ToSQL()
+	return statement
+}
+
+// ToSQL returns the sql statement and its ordered arguments.
+func (sb *SelectBuilder) ToSQL() (string, []interface{}) {
+	statement := bytes.NewBuffer(nil)
+	statement.WriteString("SELECT ")
+	if len(sb.columns) > 0 {
+		statement.WriteString(CSV(sb.columns))
+	} else {
+		statement.WriteString("*")
+	}
+	if len(sb.from) > 0 {
+		statement.WriteString(" FROM ")
+		statement.WriteString(sb.from)
+	}
+	for _, join := range sb.joins {
+		statement.WriteRune(runeSpace)
+		statement.WriteString(join)
+	}
+	if len(sb.where) > 0 {
+		statement.WriteString(" WHERE ")
+		statement.WriteString(strings.Join(sb.where, " AND "))
+	}
+	if len(sb.groupBy) > 0 {
+		statement.WriteString(" GROUP BY ")
+		statement.WriteString(CSV(sb.groupBy))
+	}
+	if len(sb.orderBy) > 0 {
+		statement.WriteString(" ORDER BY ")
+		statement.WriteString(CSV(sb.orderBy))
+	}
+
+	// limit and offset are bound last, so they can be set (or replaced) in any order relative to the other fragments.
+	args := append([]interface{}{}, sb.args...)
+	if sb.limit != nil {
+		args = append(args, *sb.limit)
+		statement.WriteString(" LIMIT $" + strconv.Itoa(len(args)))
+	}
+	if sb.offset != nil {
+		args = append(args, *sb.offset)
+		statement.WriteString(" OFFSET $" + strconv.Itoa(len(args)))
+	}
+	return statement.String(), args
+}
+
+// addArgs renumbers the parameters of a fragment after the existing arguments and appends the new arguments.
+func (sb *SelectBuilder) addArgs(fragment string, args []interface{}) string {
+	renumbered := renumberParams(fragment, len(sb.args))
+	sb.args = append(sb.args, args...)
+	return renumbered
+}
+
+// --------------------------------------------------------------------------------
+// Invocation Stubs
+// --------------------------------------------------------------------------------
+
+// Select returns a new query object for a select statement builder.
+func (i *Invocation) Select(builder *SelectBuilder) *Query {
+	statement, args := builder.ToSQL()
+	return i.Query(statement, args...)
+}
+
+// Select runs a select statement builder and returns a Query.
+func (dbc *Connection) Select(builder *SelectBuilder) *Query {
+	return dbc.DB().Invoke().Select(builder)
+}
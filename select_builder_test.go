@@ -0,0 +1,65 @@
+package spiffy
+
+import (
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+func TestSelectBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	statement, args := Select("id", "name").From("bench_object").
+		Where("category = $1", "category_1").
+		WhereIf(false, "name = $1", "skipped").
+		Where("amount > $1 and amount < $2", 1000, 2000).
+		OrderBy("id desc").
+		Limit(10).
+		Offset(20).
+		ToSQL()
+
+	assert.Equal("SELECT id,name FROM bench_object WHERE (category = $1) AND (amount > $2 and amount < $3) ORDER BY id desc LIMIT $4 OFFSET $5", statement)
+	assert.Len(args, 5)
+	assert.Equal("category_1", args[0])
+	assert.Equal(10, args[3])
+	assert.Equal(20, args[4])
+}
+
+func TestSelectBuilderLimitReplaced(t *testing.T) {
+	assert := assert.New(t)
+
+	builder := Select("id").From("bench_object").Limit(5).Offset(1).Where("category = $1", "category_1").Limit(10).Offset(20)
+	statement, args := builder.ToSQL()
+
+	assert.Equal("SELECT id FROM bench_object WHERE (category = $1) LIMIT $2 OFFSET $3", statement)
+	assert.Equal([]interface{}{"category_1", 10, 20}, args)
+	assert.Equal(args, builder.Args())
+}
+
+func TestSelectBuilderColumns(t *testing.T) {
+	assert := assert.New(t)
+
+	statement, args := Select().Columns(upsertObj{}, "uo").From("upsert_object uo").
+		Join("JOIN bench_object bo ON bo.category = uo.category AND bo.pending = $1", true).
+		Where("uo.uuid = $1", "foo").
+		ToSQL()
+
+	assert.Equal("SELECT uo.uuid,uo.timestamp_utc,uo.category FROM upsert_object uo JOIN bench_object bo ON bo.category = uo.category AND bo.pending = $1 WHERE (uo.uuid = $2)", statement)
+	assert.Len(args, 2)
+}
+
+func TestInvocationSelect(t *testing.T) {
+	assert := assert.New(t)
+	tx, err := Default().Begin()
+	assert.Nil(err)
+	defer tx.Rollback()
+
+	assert.Nil(seedObjects(10, tx))
+
+	var objs []benchObj
+	err = Default().DB().InTx(tx).Invoke().Select(
+		Select().Columns(benchObj{}, "bo").From("bench_object bo").Where("bo.pending = $1", true).Limit(3),
+	).OutMany(&objs)
+	assert.Nil(err)
+	assert.Len(objs, 3)
+}
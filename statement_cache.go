@@ -1,6 +1,7 @@
 package spiffy
 
 import (
+	"context"
 	"database/sql"
 	"sync"
 )
@@ -74,6 +75,11 @@ func (sc *StatementCache) getCachedStatement(statementID string) *sql.Stmt {
 
 // Prepare returns a cached expression for a statement, or creates and caches a new one.
 func (sc *StatementCache) Prepare(id, statementProvider string) (*sql.Stmt, error) {
+	return sc.PrepareContext(context.Background(), id, statementProvider)
+}
+
+// PrepareContext returns a cached expression for a statement, or creates and caches a new one with a context.
+func (sc *StatementCache) PrepareContext(ctx context.Context, id, statementProvider string) (*sql.Stmt, error) {
 	cached := sc.getCachedStatement(id)
 	if cached != nil {
 		return cached, nil
@@ -85,7 +91,7 @@ func (sc *StatementCache) Prepare(id, statementProvider string) (*sql.Stmt, erro
 		return stmt, nil
 	}
 
-	stmt, err := sc.dbc.Prepare(statementProvider)
+	stmt, err := sc.dbc.PrepareContext(ctx, statementProvider)
 	if err != nil {
 		return nil, err
 	}
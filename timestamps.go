@@ -0,0 +1,37 @@
+package spiffy
+
+import (
+	"reflect"
+	"time"
+)
+
+// stampValues fills in the automatic timestamp columns of a row's values, which are in the order of the columns.
+// On create, `autocreate` columns are set if the object's field is zero; `autoupdate` columns are always set.
+// If the object is a reference (or an addressable slice element) its fields are set as well.
+func stampValues(object reflect.Value, cols *ColumnCollection, values []interface{}, now time.Time, isCreate bool) error {
+	target, isSettable := settableElement(object)
+	for x, col := range cols.Columns() {
+		if !col.IsAutoUpdate && !(isCreate && col.IsAutoCreate) {
+			continue
+		}
+		if !col.IsAutoUpdate && !isZeroValue(values[x]) {
+			continue
+		}
+
+		values[x] = now
+		if isSettable {
+			if err := col.SetValue(target, &now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isZeroValue returns if a value is nil or the zero value of its type.
+func isZeroValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	return reflect.DeepEqual(value, reflect.Zero(reflect.TypeOf(value)).Interface())
+}
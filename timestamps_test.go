@@ -0,0 +1,49 @@
+package spiffy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+type timestampedObj struct {
+	ID         int        `db:"id,pk,serial"`
+	Name       string     `db:"name"`
+	CreatedUTC time.Time  `db:"created_utc,autocreate"`
+	UpdatedUTC *time.Time `db:"updated_utc,autoupdate"`
+}
+
+func (to timestampedObj) TableName() string {
+	return "timestamped_object"
+}
+
+func TestStampValues(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2017, 02, 03, 04, 05, 06, 0, time.UTC)
+	cols := Columns(timestampedObj{}).WriteColumns()
+
+	obj := &timestampedObj{Name: "foo"}
+	values := cols.ColumnValues(obj)
+	assert.Nil(stampValues(reflect.ValueOf(obj), cols, values, now, true))
+	assert.Equal(now, values[1])
+	assert.Equal(now, values[2])
+	assert.Equal(now, obj.CreatedUTC)
+	assert.NotNil(obj.UpdatedUTC)
+	assert.Equal(now, *obj.UpdatedUTC)
+
+	later := now.Add(time.Hour)
+	values = cols.ColumnValues(obj)
+	assert.Nil(stampValues(reflect.ValueOf(obj), cols, values, later, true))
+	assert.Equal(now, obj.CreatedUTC, "autocreate columns that are already set are kept")
+	assert.Equal(later, *obj.UpdatedUTC)
+
+	byValue := timestampedObj{Name: "bar"}
+	values = cols.ColumnValues(byValue)
+	assert.Nil(stampValues(reflect.ValueOf(byValue), cols, values, now, false))
+	assert.Equal(time.Time{}, values[1], "autocreate columns are not set on update")
+	assert.Equal(now, values[2])
+	assert.Nil(byValue.UpdatedUTC)
+}
@@ -0,0 +1,171 @@
+package spiffy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+	"github.com/lib/pq"
+)
+
+const (
+	// DefaultMaxAttempts is the default number of times a transaction is attempted by `InTransaction`.
+	DefaultMaxAttempts = 5
+
+	// DefaultBackoffInitial is the default delay before the first retry.
+	DefaultBackoffInitial = 10 * time.Millisecond
+
+	// DefaultBackoffMax is the default upper bound on the delay between retries.
+	DefaultBackoffMax = time.Second
+)
+
+const (
+	// pqCodeSerializationFailure is the postgres `serialization_failure` error code.
+	pqCodeSerializationFailure = "40001"
+	// pqCodeDeadlockDetected is the postgres `deadlock_detected` error code.
+	pqCodeDeadlockDetected = "40P01"
+)
+
+// TransactionAction is a unit of work run within a transaction by `InTransaction`.
+type TransactionAction func(db *DB) error
+
+// BackoffProvider returns how long to wait before a given retry; `attempt` starts at 1.
+type BackoffProvider func(attempt int) time.Duration
+
+// ExponentialBackoff returns a backoff provider that doubles the delay for each attempt up to `max`.
+// Delays are jittered between half and the full value so contending transactions spread out.
+func ExponentialBackoff(initial, max time.Duration) BackoffProvider {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for x := 1; x < attempt && delay < max; x++ {
+			delay = delay * 2
+		}
+		if delay > max {
+			delay = max
+		}
+		if half := int64(delay / 2); half > 0 {
+			return time.Duration(half + rand.Int63n(half+1))
+		}
+		return delay
+	}
+}
+
+// RetryOptions control how `InTransaction` begins and retries transactions.
+type RetryOptions struct {
+	// TxOptions are the options used to begin each transaction attempt.
+	TxOptions *TxOptions
+	// MaxAttempts is the total number of attempts, including the first, defaults to `DefaultMaxAttempts`.
+	MaxAttempts int
+	// Backoff provides the delay before each retry, defaults to `ExponentialBackoff(DefaultBackoffInitial, DefaultBackoffMax)`.
+	Backoff BackoffProvider
+}
+
+// maxAttempts returns the max attempts or a default.
+func (ro *RetryOptions) maxAttempts() int {
+	if ro == nil || ro.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return ro.MaxAttempts
+}
+
+// backoff returns the delay for an attempt.
+func (ro *RetryOptions) backoff(attempt int) time.Duration {
+	if ro == nil || ro.Backoff == nil {
+		return ExponentialBackoff(DefaultBackoffInitial, DefaultBackoffMax)(attempt)
+	}
+	return ro.Backoff(attempt)
+}
+
+// txOptions returns the transaction options.
+func (ro *RetryOptions) txOptions() *TxOptions {
+	if ro == nil {
+		return nil
+	}
+	return ro.TxOptions
+}
+
+// OptionalRetryOptions returns the first of a variadic set of retry options.
+func OptionalRetryOptions(opts ...*RetryOptions) *RetryOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return nil
+}
+
+// IsRetryable returns if an error is a postgres serialization failure (40001) or deadlock (40P01),
+// i.e. if the transaction that produced it can safely be run again.
+func IsRetryable(err error) bool {
+	return matchesError(err, func(e error) bool {
+		pqErr, isPQErr := e.(*pq.Error)
+		return isPQErr && (pqErr.Code == pqCodeSerializationFailure || pqErr.Code == pqCodeDeadlockDetected)
+	})
+}
+
+// --------------------------------------------------------------------------------
+// Transaction Runner
+// --------------------------------------------------------------------------------
+
+// InTransaction runs an action within a new transaction, committing if the action returns nil and rolling back otherwise.
+// If the transaction fails with a serialization failure or deadlock it is retried with backoff, up to the max attempts
+// of the (optional) retry options. A `EventFlagRetry` event is fired for each retry.
+//
+//	err := spiffy.Default().InTransaction(func(db *spiffy.DB) error {
+//		return db.Invoke().Update(&account)
+//	}, &spiffy.RetryOptions{TxOptions: &spiffy.TxOptions{Isolation: sql.LevelSerializable}})
+func (dbc *Connection) InTransaction(action TransactionAction, opts ...*RetryOptions) error {
+	return dbc.InTransactionContext(context.Background(), action, opts...)
+}
+
+// InTransactionContext runs an action within a new transaction bound to a context, retrying on serialization failures.
+// See `InTransaction(...)`.
+func (dbc *Connection) InTransactionContext(ctx context.Context, action TransactionAction, opts ...*RetryOptions) (err error) {
+	options := OptionalRetryOptions(opts...)
+	maxAttempts := options.maxAttempts()
+
+	for attempt := 1; ; attempt++ {
+		err = dbc.runTransaction(ctx, action, options.txOptions())
+		if err == nil || attempt >= maxAttempts || !IsRetryable(err) {
+			return
+		}
+
+		delay := options.backoff(attempt)
+		dbc.fireEvent(EventFlagRetry, fmt.Sprintf("transaction retry %d of %d", attempt, maxAttempts-1), delay, err)
+
+		select {
+		case <-ctx.Done():
+			err = contextCanceledError(ctx, err, "")
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runTransaction runs a single attempt of a transaction action.
+func (dbc *Connection) runTransaction(ctx context.Context, action TransactionAction, txOptions *TxOptions) (err error) {
+	db := dbc.DB().WithContext(ctx).InTxWithOptions(txOptions)
+	if err = db.Err(); err != nil {
+		return
+	}
+
+	committed := false
+	defer func() {
+		if r := recover(); r != nil {
+			err = exception.Nest(err, exception.New(r))
+		}
+		if err != nil && !committed {
+			if rollbackErr := db.Rollback(); rollbackErr != nil {
+				err = exception.Nest(err, rollbackErr)
+			}
+		}
+	}()
+
+	if err = action(db); err != nil {
+		return
+	}
+
+	committed = true
+	err = db.Commit()
+	return
+}
@@ -0,0 +1,90 @@
+package spiffy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	assert "github.com/blendlabs/go-assert"
+	"github.com/lib/pq"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	assert := assert.New(t)
+
+	backoff := ExponentialBackoff(10*time.Millisecond, 100*time.Millisecond)
+	for attempt := 1; attempt < 10; attempt++ {
+		delay := backoff(attempt)
+		assert.True(delay > 0)
+		assert.True(delay <= 100*time.Millisecond)
+	}
+	assert.True(backoff(1) <= 10*time.Millisecond)
+	assert.True(backoff(8) >= 50*time.Millisecond)
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsRetryable(&pq.Error{Code: "40001"}))
+	assert.True(IsRetryable(&pq.Error{Code: "40P01"}))
+	assert.False(IsRetryable(&pq.Error{Code: "23505"}))
+	assert.False(IsRetryable(fmt.Errorf("pq: could not serialize access due to concurrent update")), "only the error code is retryable, not the message")
+	assert.False(IsRetryable(fmt.Errorf("this is only a test")))
+	assert.False(IsRetryable(nil))
+}
+
+func TestConnectionInTransaction(t *testing.T) {
+	assert := assert.New(t)
+
+	var attempts int
+	err := Default().InTransaction(func(db *DB) error {
+		attempts++
+		assert.NotNil(db.Tx())
+		if attempts < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+		return db.Invoke().Exec("select 'ok!'")
+	}, &RetryOptions{Backoff: func(attempt int) time.Duration { return 0 }})
+	assert.Nil(err)
+	assert.Equal(3, attempts)
+}
+
+func TestConnectionInTransactionMaxAttempts(t *testing.T) {
+	assert := assert.New(t)
+
+	var attempts int
+	err := Default().InTransaction(func(db *DB) error {
+		attempts++
+		return &pq.Error{Code: "40P01"}
+	}, &RetryOptions{MaxAttempts: 2, Backoff: func(attempt int) time.Duration { return 0 }})
+	assert.NotNil(err)
+	assert.Equal(2, attempts)
+}
+
+func TestConnectionInTransactionRollsBack(t *testing.T) {
+	assert := assert.New(t)
+
+	err := createUpserObjectTable(nil)
+	assert.Nil(err)
+
+	obj := &upsertObj{
+		UUID:      UUIDv4().ToShortString(),
+		Timestamp: time.Now().UTC(),
+		Category:  UUIDv4().ToShortString(),
+	}
+
+	var attempts int
+	err = Default().InTransaction(func(db *DB) error {
+		attempts++
+		if createErr := db.Invoke().Create(obj); createErr != nil {
+			return createErr
+		}
+		return fmt.Errorf("this is only a test")
+	})
+	assert.NotNil(err)
+	assert.Equal(1, attempts)
+
+	exists, err := Default().Exists(obj)
+	assert.Nil(err)
+	assert.False(exists)
+}
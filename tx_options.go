@@ -0,0 +1,27 @@
+package spiffy
+
+import "database/sql"
+
+// TxOptions are the options used when beginning a transaction.
+type TxOptions struct {
+	// Isolation is the transaction isolation level, `sql.LevelDefault` uses the server default (usually READ COMMITTED).
+	Isolation sql.IsolationLevel
+	// ReadOnly starts the transaction as READ ONLY.
+	ReadOnly bool
+	// Deferrable starts the transaction as DEFERRABLE.
+	// It only has an effect on SERIALIZABLE, READ ONLY transactions.
+	Deferrable bool
+}
+
+// IsZero returns if the options are the server defaults.
+func (txo *TxOptions) IsZero() bool {
+	return txo == nil || (txo.Isolation == sql.LevelDefault && !txo.ReadOnly && !txo.Deferrable)
+}
+
+// sqlTxOptions returns the options as `database/sql` understands them.
+func (txo *TxOptions) sqlTxOptions() *sql.TxOptions {
+	if txo == nil {
+		return nil
+	}
+	return &sql.TxOptions{Isolation: txo.Isolation, ReadOnly: txo.ReadOnly}
+}
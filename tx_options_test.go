@@ -0,0 +1,22 @@
+package spiffy
+
+import (
+	"database/sql"
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+func TestTxOptionsIsZero(t *testing.T) {
+	assert := assert.New(t)
+
+	var nilOptions *TxOptions
+	assert.True(nilOptions.IsZero())
+	assert.Nil(nilOptions.sqlTxOptions())
+	assert.True((&TxOptions{}).IsZero())
+	assert.False((&TxOptions{ReadOnly: true}).IsZero())
+
+	sqlOptions := (&TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}).sqlTxOptions()
+	assert.Equal(sql.LevelSerializable, sqlOptions.Isolation)
+	assert.True(sqlOptions.ReadOnly)
+}
@@ -0,0 +1,131 @@
+package spiffy
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+
+	exception "github.com/blendlabs/go-exception"
+)
+
+var (
+	typeConvertersLock sync.RWMutex
+	typeConverters     map[reflect.Type]TypeConverter
+)
+
+// TypeConverter reads and writes a go type that the driver can't scan or encode on its own,
+// e.g. a `numeric` column into a decimal type, or an `inet` column into a `net.IP`.
+type TypeConverter struct {
+	// Decode converts a non-null value read from the database (an int64, float64, bool, []byte, string or time.Time)
+	// into a value of the type.
+	Decode func(value interface{}) (interface{}, error)
+	// Encode converts a value of the type into a value the driver can write.
+	Encode func(value interface{}) (driver.Value, error)
+}
+
+// RegisterTypeConverter registers a converter for the type of a prototype value.
+// Columns of the type (or of a pointer to it) are scanned, set and written through the converter by every mapped read and write,
+// so the type doesn't have to implement `sql.Scanner` and `driver.Valuer` itself.
+//
+//	spiffy.RegisterTypeConverter(net.IP{}, spiffy.TypeConverter{
+//		Decode: func(value interface{}) (interface{}, error) { return net.ParseIP(string(value.([]byte))), nil },
+//		Encode: func(value interface{}) (driver.Value, error) { return value.(net.IP).String(), nil },
+//	})
+func RegisterTypeConverter(prototype interface{}, converter TypeConverter) {
+	typeConvertersLock.Lock()
+	defer typeConvertersLock.Unlock()
+
+	if typeConverters == nil {
+		typeConverters = map[reflect.Type]TypeConverter{}
+	}
+	typeConverters[reflect.TypeOf(prototype)] = converter
+}
+
+// UnregisterTypeConverter removes the converter for the type of a prototype value.
+func UnregisterTypeConverter(prototype interface{}) {
+	typeConvertersLock.Lock()
+	defer typeConvertersLock.Unlock()
+	delete(typeConverters, reflect.TypeOf(prototype))
+}
+
+// typeConverterFor returns the converter registered for a type, following pointers.
+func typeConverterFor(t reflect.Type) (TypeConverter, bool) {
+	typeConvertersLock.RLock()
+	defer typeConvertersLock.RUnlock()
+
+	if len(typeConverters) == 0 || t == nil {
+		return TypeConverter{}, false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	converter, hasConverter := typeConverters[t]
+	return converter, hasConverter
+}
+
+// convertedValue is the value of a column with a type converter, encoded when the driver writes it.
+type convertedValue struct {
+	value interface{}
+}
+
+// Value implements driver.Valuer.
+func (cv convertedValue) Value() (driver.Value, error) {
+	value := reflect.ValueOf(cv.value)
+	for value.IsValid() && value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	converter, hasConverter := typeConverterFor(value.Type())
+	if !hasConverter || converter.Encode == nil {
+		return nil, exception.Newf("no type converter can encode `%s`.", value.Type().String())
+	}
+	encoded, err := converter.Encode(value.Interface())
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	return encoded, nil
+}
+
+// setConvertedValue sets a field from a value read from the database, decoding it with a type converter.
+// Values that are already of the field's type (and not just assignable to it, i.e. the bytes of a `net.IP`) are set as they are.
+func setConvertedValue(field reflect.Value, converter TypeConverter, value reflect.Value) error {
+	fieldType := field.Type()
+	elemType := fieldType
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	decoded := value
+	if value.Type() != elemType {
+		if converter.Decode == nil {
+			return exception.Newf("no type converter can decode into `%s`.", elemType.String())
+		}
+		result, err := converter.Decode(value.Interface())
+		if err != nil {
+			return exception.Wrap(err)
+		}
+		decoded = reflect.ValueOf(result)
+		if !decoded.IsValid() {
+			field.Set(reflect.Zero(fieldType))
+			return nil
+		}
+		if !decoded.Type().AssignableTo(elemType) {
+			return exception.Newf("type converter for `%s` decoded a `%s`.", elemType.String(), decoded.Type().String())
+		}
+	}
+
+	if fieldType.Kind() == reflect.Ptr {
+		ptr := reflect.New(elemType)
+		ptr.Elem().Set(decoded)
+		field.Set(ptr)
+		return nil
+	}
+	field.Set(decoded)
+	return nil
+}
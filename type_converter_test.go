@@ -0,0 +1,93 @@
+package spiffy
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+type point struct {
+	X, Y int
+}
+
+type convertedObj struct {
+	ID       int    `db:"id,pk,serial"`
+	Location point  `db:"location"`
+	Previous *point `db:"previous"`
+}
+
+func (co convertedObj) TableName() string {
+	return "converted_object"
+}
+
+func registerPointConverter() {
+	RegisterTypeConverter(point{}, TypeConverter{
+		Decode: func(value interface{}) (interface{}, error) {
+			var p point
+			_, err := fmt.Sscanf(string(value.([]byte)), "(%d,%d)", &p.X, &p.Y)
+			return p, err
+		},
+		Encode: func(value interface{}) (driver.Value, error) {
+			p := value.(point)
+			return fmt.Sprintf("(%d,%d)", p.X, p.Y), nil
+		},
+	})
+}
+
+func TestTypeConverterSetValue(t *testing.T) {
+	assert := assert.New(t)
+	registerPointConverter()
+	defer UnregisterTypeConverter(point{})
+
+	lookup := Columns(convertedObj{}).Lookup()
+	location, previous := lookup["location"], lookup["previous"]
+
+	var obj convertedObj
+	var raw interface{} = []byte("(1,2)")
+	assert.Nil(location.SetValue(&obj, &raw))
+	assert.Equal(point{X: 1, Y: 2}, obj.Location)
+
+	assert.Nil(previous.SetValue(&obj, &raw))
+	assert.NotNil(obj.Previous)
+	assert.Equal(point{X: 1, Y: 2}, *obj.Previous)
+
+	assert.Nil(location.SetValue(&obj, point{X: 3, Y: 4}))
+	assert.Equal(point{X: 3, Y: 4}, obj.Location)
+
+	var null interface{}
+	assert.Nil(previous.SetValue(&obj, &null))
+	assert.NotNil(obj.Previous, "null values leave the field as it is")
+
+	var invalid interface{} = []byte("nope")
+	assert.NotNil(location.SetValue(&obj, &invalid))
+
+	_, isScanAny := location.scanTarget().(*interface{})
+	assert.True(isScanAny)
+}
+
+func TestTypeConverterValues(t *testing.T) {
+	assert := assert.New(t)
+	registerPointConverter()
+	defer UnregisterTypeConverter(point{})
+
+	obj := convertedObj{ID: 1, Location: point{X: 1, Y: 2}}
+	values := Columns(obj).ColumnValues(obj)
+	assert.Len(values, 3)
+	assert.Equal(1, values[0])
+
+	encoded, err := values[1].(driver.Valuer).Value()
+	assert.Nil(err)
+	assert.Equal("(1,2)", encoded)
+
+	encoded, err = values[2].(driver.Valuer).Value()
+	assert.Nil(err)
+	assert.Nil(encoded)
+
+	assert.Equal(point{X: 1, Y: 2}, Columns(obj).Lookup()["location"].GetValue(obj))
+
+	UnregisterTypeConverter(point{})
+	_, err = convertedValue{value: point{}}.Value()
+	assert.NotNil(err)
+}
@@ -0,0 +1,174 @@
+package spiffy
+
+import (
+	"bytes"
+	"strconv"
+
+	exception "github.com/blendlabs/go-exception"
+)
+
+// UpsertOptions control the `ON CONFLICT` clause of `Upsert` and `UpsertMany`.
+// The zero value is the default behavior: conflicts on the primary keys update every other writable column.
+type UpsertOptions struct {
+	// ConflictColumns are the columns of a unique index to use as the conflict target instead of the primary keys.
+	ConflictColumns []string
+	// ConflictConstraint is the name of a unique constraint to use as the conflict target, i.e. `ON CONFLICT ON CONSTRAINT ...`.
+	ConflictConstraint string
+	// UpdateColumns are the columns to update on conflict, defaulting to every writable column that isn't a primary key
+	// or an `autocreate` timestamp.
+	UpdateColumns []string
+	// DoNothing skips conflicting rows instead of updating them.
+	DoNothing bool
+	// Where is a condition on the update action (without the `WHERE` keyword), e.g. `users.updated_utc < EXCLUDED.updated_utc`.
+	// Parameters in it are numbered from `$1`.
+	Where string
+	// WhereArgs are the arguments for the parameters in `Where`.
+	WhereArgs []interface{}
+}
+
+// OptionalUpsertOptions returns the first of a variadic set of upsert options, or the default options.
+func OptionalUpsertOptions(opts ...UpsertOptions) UpsertOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return UpsertOptions{}
+}
+
+// IsZero returns if the options are the default options.
+func (uo UpsertOptions) IsZero() bool {
+	return len(uo.ConflictColumns) == 0 &&
+		len(uo.ConflictConstraint) == 0 &&
+		len(uo.UpdateColumns) == 0 &&
+		!uo.DoNothing &&
+		len(uo.Where) == 0 &&
+		len(uo.WhereArgs) == 0
+}
+
+// upsertStatement builds an `INSERT ... ON CONFLICT ...` statement for a number of rows of a type.
+// Conflicting rows are updated from `EXCLUDED`, so the same statement works for one or many rows.
+// The serial and version columns, if any, are returned in that order.
+func upsertStatement(tableName string, cols *ColumnCollection, rowCount int, opts UpsertOptions) (string, error) {
+	writeCols := cols.NotReadOnly().NotSerials()
+	version := cols.Versions().FirstOrDefault()
+	lookup := writeCols.Lookup()
+
+	if len(opts.ConflictColumns) > 0 && len(opts.ConflictConstraint) > 0 {
+		return "", exception.New("upsert options cannot set both conflict columns and a conflict constraint.")
+	}
+
+	if opts.DoNothing && len(opts.Where) > 0 {
+		return "", exception.New("upsert options cannot set a where clause with do nothing.")
+	}
+
+	conflictTarget := cols.PrimaryKeys().ColumnNames()
+	if len(opts.ConflictColumns) > 0 {
+		for _, name := range opts.ConflictColumns {
+			if !cols.HasColumn(name) {
+				return "", exception.Newf("upsert conflict column `%s` is not mapped on `%s`.", name, tableName)
+			}
+		}
+		conflictTarget = opts.ConflictColumns
+	}
+
+	updateColumns := cols.NotReadOnly().NotSerials().NotPrimaryKeys().NotVersions().NotAutoCreates().ColumnNames()
+	if len(opts.UpdateColumns) > 0 {
+		for _, name := range opts.UpdateColumns {
+			col, hasColumn := lookup[name]
+			if !hasColumn {
+				return "", exception.Newf("upsert update column `%s` is not a writable column of `%s`.", name, tableName)
+			}
+			if col.IsVersion {
+				return "", exception.Newf("upsert update column `%s` is a version column, which is always incremented.", name)
+			}
+		}
+		updateColumns = opts.UpdateColumns
+	}
+
+	buffer := bytes.NewBufferString("INSERT INTO ")
+	buffer.WriteString(tableName)
+	buffer.WriteString(" (")
+	buffer.WriteString(CSV(writeCols.ColumnNames()))
+	buffer.WriteString(") VALUES ")
+
+	metaIndex := 1
+	for x := 0; x < rowCount; x++ {
+		buffer.WriteString("(")
+		for y := 0; y < writeCols.Len(); y++ {
+			buffer.WriteString("$" + strconv.Itoa(metaIndex))
+			metaIndex = metaIndex + 1
+			if y < writeCols.Len()-1 {
+				buffer.WriteRune(runeComma)
+			}
+		}
+		buffer.WriteString(")")
+		if x < rowCount-1 {
+			buffer.WriteRune(runeComma)
+		}
+	}
+
+	hasTarget := len(opts.ConflictConstraint) > 0 || len(conflictTarget) > 0
+	if hasTarget || opts.DoNothing {
+		buffer.WriteString(" ON CONFLICT")
+		if len(opts.ConflictConstraint) > 0 {
+			buffer.WriteString(" ON CONSTRAINT " + opts.ConflictConstraint)
+		} else if len(conflictTarget) > 0 {
+			buffer.WriteString(" (" + CSV(conflictTarget) + ")")
+		}
+
+		if opts.DoNothing || (len(updateColumns) == 0 && version == nil) {
+			buffer.WriteString(" DO NOTHING")
+		} else {
+			buffer.WriteString(" DO UPDATE SET ")
+			for x, name := range updateColumns {
+				buffer.WriteString(name + " = EXCLUDED." + name)
+				if x < len(updateColumns)-1 {
+					buffer.WriteRune(runeComma)
+				}
+			}
+
+			var conditions []string
+			if version != nil {
+				if len(updateColumns) > 0 {
+					buffer.WriteRune(runeComma)
+				}
+				versionColumn := tableName + "." + version.ColumnName
+				buffer.WriteString(version.ColumnName + " = " + versionColumn + " + 1")
+				conditions = append(conditions, versionColumn+" = EXCLUDED."+version.ColumnName)
+			}
+			if len(opts.Where) > 0 {
+				conditions = append(conditions, "("+renumberParams(opts.Where, metaIndex-1)+")")
+			}
+			for x, condition := range conditions {
+				if x == 0 {
+					buffer.WriteString(" WHERE ")
+				} else {
+					buffer.WriteString(" AND ")
+				}
+				buffer.WriteString(condition)
+			}
+		}
+	}
+
+	if returning := upsertReturning(cols); len(returning) > 0 {
+		buffer.WriteString(" RETURNING ")
+		for x, col := range returning {
+			buffer.WriteString(col.ColumnName)
+			if x < len(returning)-1 {
+				buffer.WriteRune(runeComma)
+			}
+		}
+	}
+	return buffer.String(), nil
+}
+
+// upsertReturning returns the columns read back by an upsert statement, in order.
+func upsertReturning(cols *ColumnCollection) []*Column {
+	var returning []*Column
+	if serial := cols.Serials().FirstOrDefault(); serial != nil {
+		returning = append(returning, serial)
+	}
+	if version := cols.Versions().FirstOrDefault(); version != nil {
+		returning = append(returning, version)
+	}
+	return returning
+}
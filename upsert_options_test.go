@@ -0,0 +1,41 @@
+package spiffy
+
+import (
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+func TestUpsertStatement(t *testing.T) {
+	a := assert.New(t)
+	cols := Columns(upsertObj{})
+
+	statement, err := upsertStatement("upsert_object", cols, 1, UpsertOptions{})
+	a.Nil(err)
+	a.Equal("INSERT INTO upsert_object (uuid,timestamp_utc,category) VALUES ($1,$2,$3) ON CONFLICT (uuid) DO UPDATE SET timestamp_utc = EXCLUDED.timestamp_utc,category = EXCLUDED.category", statement)
+
+	statement, err = upsertStatement("upsert_object", cols, 2, UpsertOptions{UpdateColumns: []string{"category"}, Where: "upsert_object.category <> $1", WhereArgs: []interface{}{"foo"}})
+	a.Nil(err)
+	a.Equal("INSERT INTO upsert_object (uuid,timestamp_utc,category) VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT (uuid) DO UPDATE SET category = EXCLUDED.category WHERE (upsert_object.category <> $7)", statement)
+
+	statement, err = upsertStatement("upsert_object", cols, 1, UpsertOptions{ConflictConstraint: "upsert_object_pkey", DoNothing: true})
+	a.Nil(err)
+	a.Equal("INSERT INTO upsert_object (uuid,timestamp_utc,category) VALUES ($1,$2,$3) ON CONFLICT ON CONSTRAINT upsert_object_pkey DO NOTHING", statement)
+
+	statement, err = upsertStatement("upsert_object", cols, 1, UpsertOptions{ConflictColumns: []string{"category"}})
+	a.Nil(err)
+	a.Equal("INSERT INTO upsert_object (uuid,timestamp_utc,category) VALUES ($1,$2,$3) ON CONFLICT (category) DO UPDATE SET timestamp_utc = EXCLUDED.timestamp_utc,category = EXCLUDED.category", statement)
+
+	statement, err = upsertStatement("versioned_object", Columns(versionedObj{}), 1, UpsertOptions{})
+	a.Nil(err)
+	a.Contains(statement, "version = versioned_object.version + 1 WHERE versioned_object.version = EXCLUDED.version RETURNING id,version")
+
+	_, err = upsertStatement("upsert_object", cols, 1, UpsertOptions{UpdateColumns: []string{"not_a_column"}})
+	a.NotNil(err)
+	_, err = upsertStatement("upsert_object", cols, 1, UpsertOptions{ConflictColumns: []string{"not_a_column"}})
+	a.NotNil(err)
+	_, err = upsertStatement("upsert_object", cols, 1, UpsertOptions{ConflictColumns: []string{"uuid"}, ConflictConstraint: "upsert_object_pkey"})
+	a.NotNil(err)
+	_, err = upsertStatement("upsert_object", cols, 1, UpsertOptions{DoNothing: true, Where: "true"})
+	a.NotNil(err)
+}
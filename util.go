@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -143,6 +144,74 @@ func paramTokensCSV(num int) string {
 	return str
 }
 
+var (
+	// paramTokenExpr matches a `$N` parameter token at the start of a string.
+	paramTokenExpr = regexp.MustCompile(`^\$(\d+)`)
+	// dollarQuoteExpr matches the opening `$$` or `$tag$` of a dollar-quoted string at the start of a string.
+	dollarQuoteExpr = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z_0-9]*)?\$`)
+)
+
+// renumberParams shifts the `$N` parameter tokens in a sql fragment by an offset, i.e. `$1` becomes `$3` with an offset of 2.
+// Text inside quoted strings, quoted identifiers and dollar-quoted strings is left as it is.
+func renumberParams(fragment string, offset int) string {
+	if offset == 0 {
+		return fragment
+	}
+
+	output := make([]byte, 0, len(fragment))
+	for x := 0; x < len(fragment); {
+		c := fragment[x]
+		if c == '\'' || c == '"' {
+			end := quotedEnd(fragment, x)
+			output = append(output, fragment[x:end]...)
+			x = end
+			continue
+		}
+		if c == '$' && (x == 0 || !isIdentifierByte(fragment[x-1])) {
+			if tag := dollarQuoteExpr.FindString(fragment[x:]); len(tag) > 0 {
+				end := len(fragment)
+				if closing := strings.Index(fragment[x+len(tag):], tag); closing >= 0 {
+					end = x + len(tag) + closing + len(tag)
+				}
+				output = append(output, fragment[x:end]...)
+				x = end
+				continue
+			}
+			if token := paramTokenExpr.FindString(fragment[x:]); len(token) > 0 {
+				index, _ := strconv.Atoi(token[1:])
+				output = append(output, "$"+strconv.Itoa(index+offset)...)
+				x = x + len(token)
+				continue
+			}
+		}
+		output = append(output, c)
+		x++
+	}
+	return string(output)
+}
+
+// quotedEnd returns the index after the quote that closes a quoted string or identifier starting at an index.
+// A doubled quote is an escaped quote and doesn't close it.
+func quotedEnd(fragment string, start int) int {
+	quote := fragment[start]
+	for x := start + 1; x < len(fragment); x++ {
+		if fragment[x] != quote {
+			continue
+		}
+		if x+1 < len(fragment) && fragment[x+1] == quote {
+			x++
+			continue
+		}
+		return x + 1
+	}
+	return len(fragment)
+}
+
+// isIdentifierByte returns if a byte can be part of an unquoted identifier, i.e. the `$1` in `foo$1` is not a parameter.
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
 // makeNewDatabaseMapped returns a new instance of a database mapped type.
 func makeNewDatabaseMapped(t reflect.Type) (DatabaseMapped, error) {
 	newInterface := reflect.New(t).Interface()
@@ -48,3 +48,17 @@ func TestMakeSliceOfType(t *testing.T) {
 	a.Nil(allErr)
 	a.NotEmpty(*sliceOfT)
 }
+
+func TestRenumberParams(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("a = $1 and b = $2", renumberParams("a = $1 and b = $2", 0))
+	assert.Equal("a = $3 and b = $4", renumberParams("a = $1 and b = $2", 2))
+	assert.Equal("a = $11", renumberParams("a = $10", 1))
+
+	// parameters can't appear in quoted text, so it is left as it is.
+	assert.Equal(`a = '$1' and b = $3`, renumberParams(`a = '$1' and b = $1`, 2))
+	assert.Equal(`a = 'it''s $1' and "col$1" = $3`, renumberParams(`a = 'it''s $1' and "col$1" = $1`, 2))
+	assert.Equal(`a = $$ $1 $$ and b = $tag$ $1 $tag$ and c = $3`, renumberParams(`a = $$ $1 $$ and b = $tag$ $1 $tag$ and c = $1`, 2))
+	assert.Equal("foo$1 = $3", renumberParams("foo$1 = $1", 2))
+}
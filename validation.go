@@ -0,0 +1,122 @@
+package spiffy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks an object against the `required`, `maxlen` and `enum` rules of its column tags and,
+// if it implements `Validator`, its own rules. It returns a `*ValidationError` listing every failure, or nil.
+func Validate(object DatabaseMapped) error {
+	cols := getCachedColumnCollectionFromInstance(object)
+	if err := cols.Err(); err != nil {
+		return err
+	}
+	return validate(object, cols)
+}
+
+// validate checks the columns of a collection on an object, and the object's own rules.
+func validate(object DatabaseMapped, cols *ColumnCollection) error {
+	var fields []FieldError
+	value := reflectValue(object)
+	for _, col := range cols.Columns() {
+		if message := validateColumn(col, col.fieldValue(value, false)); len(message) > 0 {
+			fields = append(fields, FieldError{Field: col.FieldName, Column: col.ColumnName, Message: message})
+		}
+	}
+
+	if validator, isValidator := object.(Validator); isValidator {
+		if err := validator.Validate(); err != nil {
+			if typed, isTyped := err.(*ValidationError); isTyped {
+				fields = append(fields, typed.Fields...)
+			} else {
+				fields = append(fields, FieldError{Message: err.Error()})
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Table: object.TableName(), Fields: fields}
+	}
+	return nil
+}
+
+// validateMany validates each element of a slice, returning the first failure.
+func validateMany(sliceValue reflect.Value, cols *ColumnCollection) error {
+	for x := 0; x < sliceValue.Len(); x++ {
+		object, isSettable := settableElement(sliceValue.Index(x))
+		if !isSettable {
+			object = sliceValue.Index(x).Interface()
+		}
+		if typed, isTyped := object.(DatabaseMapped); isTyped {
+			if err := validate(typed, cols); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateColumn returns a message describing why a field fails its column's rules, or an empty string.
+func validateColumn(col Column, field reflect.Value) string {
+	// timestamps the library stamps on write are never missing, even though they're zero when validated.
+	required := col.IsRequired && !col.IsAutoCreate && !col.IsAutoUpdate
+	if !field.IsValid() {
+		// the field is in a nil embedded struct.
+		if required {
+			return "is required"
+		}
+		return ""
+	}
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			if required {
+				return "is required"
+			}
+			return ""
+		}
+		field = field.Elem()
+	}
+	if !field.IsValid() || !field.CanInterface() {
+		return ""
+	}
+
+	if required && isMissing(field) {
+		return "is required"
+	}
+
+	if col.MaxLength > 0 && field.Kind() == reflect.String {
+		if length := utf8.RuneCountInString(field.String()); length > col.MaxLength {
+			return fmt.Sprintf("is %d characters, the maximum is %d", length, col.MaxLength)
+		}
+	}
+
+	if len(col.Enum) > 0 {
+		text := fmt.Sprint(field.Interface())
+		if len(text) == 0 && !col.IsRequired {
+			return ""
+		}
+		for _, allowed := range col.Enum {
+			if text == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("is %q, it must be one of %s", text, strings.Join(col.Enum, ", "))
+	}
+	return ""
+}
+
+// isMissing returns if a required field has no value; numbers and booleans always have one.
+func isMissing(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.String:
+		return len(field.String()) == 0
+	case reflect.Slice, reflect.Map:
+		return field.IsNil()
+	case reflect.Struct:
+		return reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface())
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package spiffy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	assert "github.com/blendlabs/go-assert"
+)
+
+type validatedObj struct {
+	ID     int     `db:"id,pk,serial"`
+	Name   string  `db:"name,required,maxlen=8"`
+	Status string  `db:"status,enum=active|inactive"`
+	Email  *string `db:"email,required"`
+	Count  int     `db:"count,required"`
+
+	failCustom bool `db:"-"`
+}
+
+func (vo validatedObj) TableName() string {
+	return "validated_object"
+}
+
+func (vo validatedObj) Validate() error {
+	if vo.failCustom {
+		return fmt.Errorf("custom rule failed")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+
+	email := "foo@bar.com"
+	a.Nil(Validate(validatedObj{Name: "foo", Status: "active", Email: &email}))
+	a.Nil(Validate(validatedObj{Name: "foo", Email: &email}), "an empty enum value is allowed unless required")
+
+	err := Validate(&validatedObj{Name: "much too long", Status: "deleted", failCustom: true})
+	a.NotNil(err)
+	a.True(IsValidationError(err))
+
+	typed := err.(*ValidationError)
+	a.Equal("validated_object", typed.Table)
+	a.Len(typed.Fields, 4)
+	a.Equal("name", typed.Fields[0].Column)
+	a.Equal("status", typed.Fields[1].Column)
+	a.Equal("email", typed.Fields[2].Column)
+	a.Equal("custom rule failed", typed.Fields[3].Message)
+	a.Contains(err.Error(), "email is required")
+
+	err = Validate(&validatedObj{Email: &email})
+	a.NotNil(err)
+	a.Equal("spiffy: validation failed for `validated_object`: name is required", err.Error())
+}
+
+type stampedValidatedObj struct {
+	ID         int        `db:"id,pk,serial"`
+	CreatedUTC time.Time  `db:"created_utc,required,autocreate"`
+	UpdatedUTC *time.Time `db:"updated_utc,required,autoupdate"`
+}
+
+func (svo stampedValidatedObj) TableName() string {
+	return "stamped_validated_object"
+}
+
+func TestValidateStampedColumns(t *testing.T) {
+	a := assert.New(t)
+	a.Nil(Validate(stampedValidatedObj{}), "autocreate and autoupdate columns are set when written, so they aren't missing")
+}
+
+func TestValidateMany(t *testing.T) {
+	a := assert.New(t)
+
+	email := "foo@bar.com"
+	objs := []validatedObj{{Name: "foo", Email: &email}, {Name: "bar"}}
+	err := validateMany(reflectValue(objs), Columns(validatedObj{}))
+	a.True(IsValidationError(err))
+
+	objs[1].Email = &email
+	a.Nil(validateMany(reflectValue(objs), Columns(validatedObj{})))
+}
+
+func TestInvocationValidatesBeforeWrites(t *testing.T) {
+	a := assert.New(t)
+	tx, err := Default().Begin()
+	a.Nil(err)
+	defer tx.Rollback()
+
+	// the table doesn't exist, so any sql that ran would fail with a postgres error instead.
+	err = Default().CreateInTx(&validatedObj{}, tx)
+	a.True(IsValidationError(err))
+
+	err = Default().CreateManyInTx([]validatedObj{{}}, tx)
+	a.True(IsValidationError(err))
+
+	err = Default().UpdateInTx(&validatedObj{ID: 1}, tx)
+	a.True(IsValidationError(err))
+
+	err = Default().UpsertInTx(&validatedObj{}, tx)
+	a.True(IsValidationError(err))
+}